@@ -0,0 +1,134 @@
+package osciris
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/chromedp/cdproto/network"
+)
+
+// BlockPolicy политика блокировки запросов, применяемая ко всем вкладкам браузера
+type BlockPolicy struct {
+	// ResourceTypes типы ресурсов для блокировки (network.ResourceTypeImage, network.ResourceTypeFont,
+	// network.ResourceTypeMedia, network.ResourceTypeStylesheet и т.д.)
+	ResourceTypes []network.ResourceType
+
+	// URLPatterns шаблоны URL для блокировки ('*' - любое количество символов, '?' - ровно один символ)
+	// Например: "*://*.doubleclick.net/*", "*google-analytics.com*"
+	URLPatterns []string
+
+	// URLRegexps регулярные выражения URL для блокировки
+	URLRegexps []string
+}
+
+// blocker скомпилированная политика блокировки
+type blocker struct {
+	types    map[network.ResourceType]bool
+	patterns []*regexp.Regexp
+	regexps  []*regexp.Regexp
+}
+
+// compile проверяет политику и компилирует шаблоны
+func (bp *BlockPolicy) compile() (*blocker, error) {
+	bl := &blocker{types: make(map[network.ResourceType]bool)}
+
+	for _, t := range bp.ResourceTypes {
+		bl.types[t] = true
+	}
+	for _, pattern := range bp.URLPatterns {
+		re, err := compileURLPattern(pattern)
+		if err != nil {
+			return nil, err
+		}
+		bl.patterns = append(bl.patterns, re)
+	}
+	for _, expr := range bp.URLRegexps {
+		re, err := regexp.Compile(expr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid URL regexp %q: %w", expr, err)
+		}
+		bl.regexps = append(bl.regexps, re)
+	}
+
+	return bl, nil
+}
+
+// requestPatterns возвращает шаблоны Fetch domain, под которые попадают блокируемые запросы
+// Регулярные выражения не поддерживаются Fetch domain, поэтому при их наличии перехватываются все запросы
+func (bp *BlockPolicy) requestPatterns() []RequestPattern {
+	if len(bp.URLRegexps) > 0 {
+		return []RequestPattern{{URL: "*"}}
+	}
+
+	var patterns []RequestPattern
+	for _, t := range bp.ResourceTypes {
+		patterns = append(patterns, RequestPattern{ResourceType: t})
+	}
+	for _, pattern := range bp.URLPatterns {
+		patterns = append(patterns, RequestPattern{URL: pattern})
+	}
+	return patterns
+}
+
+// match проверяет, нужно ли блокировать запрос
+func (bl *blocker) match(req *InterceptedRequest) bool {
+	if bl.types[req.ResourceType] {
+		return true
+	}
+	for _, re := range bl.patterns {
+		if re.MatchString(req.URL) {
+			return true
+		}
+	}
+	for _, re := range bl.regexps {
+		if re.MatchString(req.URL) {
+			return true
+		}
+	}
+	return false
+}
+
+// Block блокирует запросы страницы согласно политике независимо от обработчиков Intercept
+// Возвращает функцию, отменяющую блокировку
+func (p *Page) Block(policy *BlockPolicy) (func() error, error) {
+	bl, err := policy.compile()
+	if err != nil {
+		return nil, err
+	}
+
+	patterns := policy.requestPatterns()
+	if len(patterns) == 0 {
+		return func() error { return nil }, nil
+	}
+
+	// Блокировка проверяется до обработчиков Intercept, поэтому они не могут ее отменить
+	r := &route{block: bl}
+	for _, pattern := range patterns {
+		re, err := compileURLPattern(pattern.URL)
+		if err != nil {
+			return nil, err
+		}
+		r.patterns = append(r.patterns, pattern)
+		r.urls = append(r.urls, re)
+	}
+
+	router := p.fetchRouter()
+	if err := router.addRoute(r); err != nil {
+		return nil, fmt.Errorf("failed to enable interception: %w", err)
+	}
+
+	return func() error {
+		return router.removeRoute(r)
+	}, nil
+}
+
+// applyBlockPolicy применяет BrowserOptions.Block к странице вкладки
+func (b *Browser) applyBlockPolicy(p *Page) error {
+	if b.options.Block == nil {
+		return nil
+	}
+	if _, err := p.Block(b.options.Block); err != nil {
+		return fmt.Errorf("failed to apply block policy: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,271 @@
+package osciris
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	"github.com/chromedp/cdproto/cdp"
+	cdruntime "github.com/chromedp/cdproto/runtime"
+	"github.com/chromedp/cdproto/target"
+	"github.com/chromedp/chromedp"
+	fp "github.com/vitaliitsarov/fingerprint-injector-go"
+)
+
+// childTargetFilter типы дочерних целей, к которым подключается вкладка
+var childTargetFilter = target.Filter{
+	{Type: "iframe"},
+	{Type: "worker"},
+	{Type: "shared_worker"},
+	{Type: "service_worker"},
+	{Exclude: true},
+}
+
+// workerOverrideScript подменяет значения navigator и часовой пояс в глобальной области воркера; %s - workerOverrides в JSON
+// Смещение getTimezoneOffset вычисляется для каждой даты по правилам пояса, чтобы учитывать переход на летнее время
+const workerOverrideScript = `(function (o) {
+	const proto = Object.getPrototypeOf(navigator);
+	const define = (name, value) => {
+		if (value === undefined || value === null) return;
+		Object.defineProperty(proto, name, {get: () => value, configurable: true, enumerable: true});
+	};
+	define('userAgent', o.userAgent);
+	define('appVersion', o.userAgent ? o.userAgent.replace(/^Mozilla\//, '') : undefined);
+	define('platform', o.platform);
+	define('language', o.language);
+	define('languages', o.languages ? Object.freeze(o.languages.slice()) : undefined);
+	define('hardwareConcurrency', o.hardwareConcurrency);
+	define('deviceMemory', o.deviceMemory);
+	define('webdriver', false);
+	if (o.timezone) {
+		const DateTimeFormat = Intl.DateTimeFormat;
+		const zoned = new DateTimeFormat('en-US', {
+			timeZone: o.timezone, hourCycle: 'h23',
+			year: 'numeric', month: 'numeric', day: 'numeric', hour: 'numeric', minute: 'numeric', second: 'numeric',
+		});
+		const patched = function (locales, options) {
+			return new DateTimeFormat(locales, Object.assign({timeZone: o.timezone}, options));
+		};
+		patched.prototype = DateTimeFormat.prototype;
+		patched.supportedLocalesOf = DateTimeFormat.supportedLocalesOf;
+		Intl.DateTimeFormat = patched;
+		Date.prototype.getTimezoneOffset = function () {
+			const time = this.getTime();
+			if (isNaN(time)) return NaN;
+			const parts = {};
+			for (const part of zoned.formatToParts(time)) parts[part.type] = part.value;
+			const local = new Date(0);
+			local.setUTCFullYear(+parts.year, +parts.month - 1, +parts.day);
+			local.setUTCHours(+parts.hour, +parts.minute, +parts.second);
+			return Math.round((Math.floor(time / 1000) * 1000 - local.getTime()) / 60000);
+		};
+	}
+})(%s)`
+
+// workerOverrides значения, подменяемые в воркерах
+type workerOverrides struct {
+	UserAgent           string   `json:"userAgent,omitempty"`
+	Platform            string   `json:"platform,omitempty"`
+	Language            string   `json:"language,omitempty"`
+	Languages           []string `json:"languages,omitempty"`
+	HardwareConcurrency int      `json:"hardwareConcurrency,omitempty"`
+	DeviceMemory        int      `json:"deviceMemory,omitempty"`
+	Timezone            string   `json:"timezone,omitempty"`
+}
+
+// childTargets применяет fingerprint в воркерах и out-of-process iframe вкладки до выполнения их скриптов
+// Дочерние цели подключаются flat-сессиями собственного соединения Browser, а подключение chromedp к вкладке не меняется
+type childTargets struct {
+	browser   *Browser
+	conn      *devtoolsConn
+	ctx       context.Context
+	injector  *fp.Injector
+	userAgent chromedp.ActionFunc
+	emulation *pageEmulation
+	overrides *workerOverrides
+
+	// sessions flat-сессии вкладки и ее фреймов, события которых передаются в onEvent
+	mu       sync.Mutex
+	sessions map[target.SessionID]bool
+	closed   bool
+}
+
+// applyChildTargets подключает вкладку к дочерним целям, если есть что в них подменять
+func (b *Browser) applyChildTargets(p *Page) error {
+	fingerprint, injector := b.pageFingerprint(p)
+	emulation := b.pageEmulation(p)
+	overrides := newWorkerOverrides(fingerprint, emulation)
+	if injector == nil && overrides == nil {
+		return nil
+	}
+
+	userAgent, err := b.pageUserAgent(p)
+	if err != nil {
+		return err
+	}
+
+	// Подключаемся к вкладке, чтобы знать ее target
+	if err := p.run(); err != nil {
+		return err
+	}
+	conn, err := b.devtoolsConn()
+	if err != nil {
+		return fmt.Errorf("failed to enable auto-attach to child targets: %w", err)
+	}
+
+	c := &childTargets{
+		browser:   b,
+		conn:      conn,
+		ctx:       p.ctx,
+		injector:  injector,
+		userAgent: userAgent,
+		emulation: emulation,
+		overrides: overrides,
+		sessions:  make(map[target.SessionID]bool),
+	}
+
+	ctx, cancel := context.WithTimeout(p.ctx, b.options.Timeout)
+	defer cancel()
+
+	sessionID, err := target.AttachToTarget(p.TargetID()).WithFlatten(true).Do(cdp.WithExecutor(ctx, conn.session("")))
+	if err != nil {
+		return fmt.Errorf("failed to enable auto-attach to child targets: %w", err)
+	}
+	c.add(sessionID)
+	go c.detach(sessionID)
+
+	// Собственная сессия вкладки ставит дочерние цели на паузу до применения подмены
+	err = target.SetAutoAttach(true, true).WithFlatten(true).WithFilter(childTargetFilter).Do(cdp.WithExecutor(ctx, conn.session(sessionID)))
+	if err != nil {
+		return fmt.Errorf("failed to enable auto-attach to child targets: %w", err)
+	}
+	return nil
+}
+
+// pageFingerprint возвращает fingerprint и injector вкладки страницы с учетом ее BrowserContext
+func (b *Browser) pageFingerprint(p *Page) (*fp.Fingerprint, *fp.Injector) {
+	if bc := p.context; bc != nil && bc.injector != nil {
+		return bc.fingerprint, bc.injector
+	}
+	return b.options.Fingerprint, b.injector
+}
+
+// newWorkerOverrides собирает значения для воркеров из fingerprint и эмуляции вкладки (nil - подменять нечего)
+func newWorkerOverrides(f *fp.Fingerprint, e *pageEmulation) *workerOverrides {
+	o := &workerOverrides{}
+	if f != nil {
+		o.UserAgent = f.UserAgent
+		o.Platform = f.Platform
+		o.HardwareConcurrency = f.HardwareConcurrency
+		o.DeviceMemory = f.DeviceMemory
+	}
+	if e != nil {
+		o.Language = e.Locale
+		o.Languages = e.Languages
+		o.Timezone = e.Timezone
+	}
+
+	if o.UserAgent == "" && o.Platform == "" && o.Language == "" && len(o.Languages) == 0 &&
+		o.HardwareConcurrency == 0 && o.DeviceMemory == 0 && o.Timezone == "" {
+		return nil
+	}
+	return o
+}
+
+// add начинает передавать события сессии id в onEvent
+func (c *childTargets) add(id target.SessionID) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.closed {
+		return
+	}
+	c.sessions[id] = true
+	c.conn.listen(id, c.onEvent)
+}
+
+// remove перестает передавать события сессии id
+func (c *childTargets) remove(id target.SessionID) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	delete(c.sessions, id)
+	c.conn.listen(id, nil)
+}
+
+// detach отключает сессию вкладки после закрытия страницы; дочерние сессии Chrome отключает вместе с ней
+func (c *childTargets) detach(id target.SessionID) {
+	select {
+	case <-c.ctx.Done():
+	case <-c.conn.done:
+	}
+
+	c.mu.Lock()
+	c.closed = true
+	for s := range c.sessions {
+		c.conn.listen(s, nil)
+	}
+	c.sessions = nil
+	c.mu.Unlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), c.browser.options.Timeout)
+	defer cancel()
+	// Ошибку игнорируем: вкладка или соединение могли закрыться раньше страницы
+	target.DetachFromTarget().WithSessionID(id).Do(cdp.WithExecutor(ctx, c.conn.session("")))
+}
+
+// onEvent получает события сессий вкладки и ее фреймов; подключение обрабатывается в отдельной горутине
+func (c *childTargets) onEvent(ev any) {
+	switch ev := ev.(type) {
+	case *target.EventAttachedToTarget:
+		// Вложенные цели фрейма приходят в его сессии, поэтому ее события слушаются до начала подмены
+		if ev.TargetInfo.Type == "iframe" {
+			c.add(ev.SessionID)
+		}
+		go c.setup(ev.SessionID, ev.TargetInfo, ev.WaitingForDebugger)
+
+	case *target.EventDetachedFromTarget:
+		c.remove(ev.SessionID)
+	}
+}
+
+// setup применяет fingerprint в дочерней цели и запускает ее
+func (c *childTargets) setup(id target.SessionID, info *target.Info, waiting bool) {
+	s := c.conn.session(id)
+	ctx, cancel := context.WithTimeout(c.ctx, c.browser.options.Timeout)
+	defer cancel()
+	ctx = cdp.WithExecutor(ctx, s)
+
+	// Цель запускается на отдельном контексте, даже если подмена исчерпала таймаут
+	if waiting {
+		defer func() {
+			resumeCtx, resumeCancel := context.WithTimeout(c.ctx, c.browser.options.Timeout)
+			defer resumeCancel()
+			cdruntime.RunIfWaitingForDebugger().Do(cdp.WithExecutor(resumeCtx, s))
+		}()
+	}
+
+	// Ошибки игнорируем: часть команд недоступна в дочерних целях, а цель могла закрыться до подключения
+	switch info.Type {
+	case "iframe":
+		if c.injector != nil {
+			c.injector.ApplyAll(ctx).Do(ctx)
+		}
+		if c.userAgent != nil {
+			c.userAgent.Do(ctx)
+		}
+		if c.emulation != nil {
+			c.emulation.overrides().Do(ctx)
+		}
+		// Вложенные iframe и воркеры фрейма подключаются так же, как у вкладки
+		target.SetAutoAttach(true, true).WithFlatten(true).WithFilter(childTargetFilter).Do(ctx)
+
+	case "worker", "shared_worker", "service_worker":
+		if c.overrides != nil {
+			if data, err := json.Marshal(c.overrides); err == nil {
+				cdruntime.Evaluate(fmt.Sprintf(workerOverrideScript, data)).Do(ctx)
+			}
+		}
+	}
+}
@@ -0,0 +1,289 @@
+package osciris
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/chromedp/cdproto/browser"
+	"github.com/chromedp/cdproto/emulation"
+	"github.com/chromedp/chromedp"
+	fp "github.com/vitaliitsarov/fingerprint-injector-go"
+)
+
+// chromeFullVersions полные версии стабильных сборок Chrome; User-Agent содержит только основную версию (119.0.0.0),
+// а Sec-CH-UA-Full-Version-List с нулевой сборкой выдает подмену
+var chromeFullVersions = map[int]string{
+	116: "116.0.5845.188",
+	117: "117.0.5938.149",
+	118: "118.0.5993.117",
+	119: "119.0.6045.199",
+	120: "120.0.6099.224",
+	121: "121.0.6167.184",
+	122: "122.0.6261.128",
+	123: "123.0.6312.122",
+	124: "124.0.6367.207",
+	125: "125.0.6422.141",
+	126: "126.0.6478.126",
+	127: "127.0.6533.119",
+	128: "128.0.6613.137",
+	129: "129.0.6668.100",
+	130: "130.0.6723.116",
+	131: "131.0.6778.204",
+	132: "132.0.6834.159",
+	133: "133.0.6943.141",
+	134: "134.0.6998.165",
+	135: "135.0.7049.114",
+	136: "136.0.7103.113",
+	137: "137.0.7151.119",
+	138: "138.0.7204.183",
+	139: "139.0.7258.154",
+	140: "140.0.7339.207",
+	141: "141.0.7390.122",
+	142: "142.0.7444.175",
+}
+
+// chromeBuildsPerMajor средний прирост номера сборки Chromium за одну основную версию
+const chromeBuildsPerMajor = 55
+
+// windowsPlatformVersion версия платформы Windows 11; User-Agent Windows 10 и 11 одинаков ("Windows NT 10.0")
+const windowsPlatformVersion = "15.0.0"
+
+// macOSPlatformVersion версия macOS для client hints; User-Agent Chrome заморожен на 10_15_7
+const macOSPlatformVersion = "15.6.1"
+
+// GREASE-бренд Chrome (алгоритм GenerateBrandVersionList из Chromium)
+var (
+	greaseChars    = []string{" ", "(", ":", "-", ".", "/", ")", ";", "=", "?", "_"}
+	greaseVersions = []string{"8", "99", "24"}
+	brandOrders    = [][3]int{{0, 1, 2}, {0, 2, 1}, {1, 0, 2}, {1, 2, 0}, {2, 0, 1}, {2, 1, 0}}
+)
+
+// UserAgentMetadata возвращает client hints (navigator.userAgentData и заголовки Sec-CH-UA*),
+// согласованные с User-Agent fingerprint: бренды и полную версию Chrome, платформу, ее версию,
+// архитектуру, модель и признак мобильного устройства
+// Возвращает nil, если User-Agent не принадлежит Chrome или Edge либо ОС не отправляет client hints (iOS)
+func UserAgentMetadata(f *fp.Fingerprint) *emulation.UserAgentMetadata {
+	if f == nil {
+		return nil
+	}
+	return userAgentMetadata(f.UserAgent)
+}
+
+// userAgentMetadata строит client hints по строке User-Agent
+func userAgentMetadata(userAgent string) *emulation.UserAgentMetadata {
+	uaOS := userAgentOS(userAgent)
+	platform := clientHintsPlatform(uaOS)
+	major := chromeMajorVersion(userAgent)
+	seed, err := strconv.Atoi(major)
+	if platform == "" || err != nil {
+		return nil
+	}
+
+	fullVersion := chromeFullVersion(userAgent)
+	brand, brandMajor, brandFull := "Google Chrome", major, fullVersion
+	if _, rest, ok := strings.Cut(userAgent, "Edg/"); ok {
+		brandFull, _, _ = strings.Cut(rest, " ")
+		brandMajor, _, _ = strings.Cut(brandFull, ".")
+		brand = "Microsoft Edge"
+	}
+
+	md := &emulation.UserAgentMetadata{
+		Platform:    platform,
+		FormFactors: []string{"Desktop"},
+	}
+	md.Brands, md.FullVersionList = clientHintsBrands(seed, brand, major, brandMajor, fullVersion, brandFull)
+
+	switch uaOS {
+	case osWindows:
+		md.PlatformVersion = windowsPlatformVersion
+		md.Architecture, md.Bitness = "x86", "64"
+		if strings.Contains(userAgent, "ARM64") {
+			md.Architecture = "arm"
+		}
+		md.Wow64 = strings.Contains(userAgent, "WOW64")
+	case osMacOS:
+		// Замороженная версия из User-Agent заменяется реальной, нестандартная берется как есть
+		md.PlatformVersion = macOSPlatformVersion
+		if _, rest, ok := strings.Cut(userAgent, "Mac OS X "); ok {
+			version, _, _ := strings.Cut(rest, ")")
+			version, _, _ = strings.Cut(version, ";")
+			if version = strings.ReplaceAll(version, "_", "."); version != "10.15.7" {
+				md.PlatformVersion = version
+			}
+		}
+		md.Architecture, md.Bitness = "x86", "64"
+	case osLinux:
+		md.Architecture, md.Bitness = "x86", "64"
+		if strings.Contains(userAgent, "aarch64") || strings.Contains(userAgent, "arm") {
+			md.Architecture = "arm"
+		}
+	case osAndroid:
+		md.Mobile = strings.Contains(userAgent, "Mobile")
+		md.FormFactors = []string{"Tablet"}
+		if md.Mobile {
+			md.FormFactors = []string{"Mobile"}
+		}
+		if _, rest, ok := strings.Cut(userAgent, "Android "); ok {
+			details, _, _ := strings.Cut(rest, ")")
+			version, model, _ := strings.Cut(details, ";")
+			md.PlatformVersion = androidPlatformVersion(strings.TrimSpace(version))
+			md.Model = strings.TrimSpace(model)
+		}
+	}
+
+	return md
+}
+
+// clientHintsBrands возвращает списки брендов для Sec-CH-UA и Sec-CH-UA-Full-Version-List
+// Порядок брендов и GREASE-бренд зависят от основной версии Chrome так же, как в самом Chrome
+func clientHintsBrands(seed int, brand, chromiumMajor, brandMajor, chromiumFull, brandFull string) (brands, fullVersionList []*emulation.UserAgentBrandVersion) {
+	greaseBrand := "Not" + greaseChars[seed%len(greaseChars)] + "A" + greaseChars[(seed+1)%len(greaseChars)] + "Brand"
+	greaseVersion := greaseVersions[seed%len(greaseVersions)]
+	order := brandOrders[seed%len(brandOrders)]
+
+	brands = make([]*emulation.UserAgentBrandVersion, 3)
+	brands[order[0]] = &emulation.UserAgentBrandVersion{Brand: greaseBrand, Version: greaseVersion}
+	brands[order[1]] = &emulation.UserAgentBrandVersion{Brand: "Chromium", Version: chromiumMajor}
+	brands[order[2]] = &emulation.UserAgentBrandVersion{Brand: brand, Version: brandMajor}
+
+	fullVersionList = make([]*emulation.UserAgentBrandVersion, 3)
+	fullVersionList[order[0]] = &emulation.UserAgentBrandVersion{Brand: greaseBrand, Version: greaseVersion + ".0.0.0"}
+	fullVersionList[order[1]] = &emulation.UserAgentBrandVersion{Brand: "Chromium", Version: chromiumFull}
+	fullVersionList[order[2]] = &emulation.UserAgentBrandVersion{Brand: brand, Version: brandFull}
+	return brands, fullVersionList
+}
+
+// chromeFullVersion возвращает полную версию Chrome: из User-Agent, если в нем указана сборка,
+// иначе версию стабильной сборки для основной версии
+func chromeFullVersion(userAgent string) string {
+	_, rest, ok := strings.Cut(userAgent, "Chrome/")
+	if !ok {
+		return ""
+	}
+	version, _, _ := strings.Cut(rest, " ")
+	major, build, _ := strings.Cut(version, ".")
+	n, err := strconv.Atoi(major)
+	if build != "0.0.0" || err != nil {
+		return version
+	}
+	if full, ok := chromeFullVersions[n]; ok {
+		return full
+	}
+	return estimateChromeFullVersion(n)
+}
+
+// estimateChromeFullVersion оценивает номер сборки для версии вне таблицы по ближайшей известной
+func estimateChromeFullVersion(major int) string {
+	nearest := 0
+	for known := range chromeFullVersions {
+		if nearest == 0 || abs(known-major) < abs(nearest-major) {
+			nearest = known
+		}
+	}
+	parts := strings.Split(chromeFullVersions[nearest], ".")
+	build, _ := strconv.Atoi(parts[2])
+	build += (major - nearest) * chromeBuildsPerMajor
+	return fmt.Sprintf("%d.0.%d.%s", major, build, parts[3])
+}
+
+// abs возвращает модуль числа
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+// androidPlatformVersion дополняет версию Android до трех компонент ("10" -> "10.0.0")
+func androidPlatformVersion(version string) string {
+	if version == "" {
+		return ""
+	}
+	for strings.Count(version, ".") < 2 {
+		version += ".0"
+	}
+	return version
+}
+
+// clientHintsPlatform возвращает значение navigator.userAgentData.platform для ОС
+func clientHintsPlatform(uaOS fingerprintOS) string {
+	switch uaOS {
+	case osWindows:
+		return "Windows"
+	case osMacOS:
+		return "macOS"
+	case osLinux:
+		return "Linux"
+	case osAndroid:
+		return "Android"
+	}
+	return ""
+}
+
+// chromeMajorVersion возвращает основную версию Chrome из User-Agent
+func chromeMajorVersion(userAgent string) string {
+	_, rest, ok := strings.Cut(userAgent, "Chrome/")
+	if !ok {
+		return ""
+	}
+	major, _, _ := strings.Cut(rest, ".")
+	return major
+}
+
+// userAgentOverride подменяет User-Agent, navigator.platform, Accept-Language и client hints в текущей цели
+func userAgentOverride(userAgent, platform, acceptLanguage string) chromedp.ActionFunc {
+	return userAgentMetadataOverride(userAgent, platform, acceptLanguage, userAgentMetadata(userAgent))
+}
+
+// userAgentMetadataOverride подменяет User-Agent, navigator.platform и Accept-Language с готовыми client hints (nil - без них)
+func userAgentMetadataOverride(userAgent, platform, acceptLanguage string, md *emulation.UserAgentMetadata) chromedp.ActionFunc {
+	return func(ctx context.Context) error {
+		params := emulation.SetUserAgentOverride(userAgent).WithPlatform(platform)
+		if acceptLanguage != "" {
+			params = params.WithAcceptLanguage(acceptLanguage)
+		}
+		if md != nil {
+			params = params.WithUserAgentMetadata(md)
+		}
+		return params.Do(ctx)
+	}
+}
+
+// pageUserAgent возвращает подмену User-Agent, client hints и Accept-Language вкладки страницы (nil - подменять нечего)
+func (b *Browser) pageUserAgent(p *Page) (chromedp.ActionFunc, error) {
+	var userAgent, platform string
+	if f, _ := b.pageFingerprint(p); f != nil {
+		userAgent, platform = f.UserAgent, f.Platform
+	}
+	acceptLanguage := b.pageEmulation(p).acceptLanguage()
+	if userAgent == "" && acceptLanguage == "" {
+		return nil, nil
+	}
+
+	// Override без User-Agent заменил бы его пустой строкой, поэтому для одного Accept-Language берется настоящий
+	if userAgent == "" {
+		err := b.runBrowser(func(ctx context.Context) error {
+			var err error
+			_, _, _, userAgent, _, err = browser.GetVersion().Do(ctx)
+			return err
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to get browser user agent: %w", err)
+		}
+	}
+	return userAgentOverride(userAgent, platform, acceptLanguage), nil
+}
+
+// applyUserAgent подменяет User-Agent, client hints и Accept-Language во вкладке страницы
+func (b *Browser) applyUserAgent(p *Page) error {
+	override, err := b.pageUserAgent(p)
+	if err != nil || override == nil {
+		return err
+	}
+	if err := p.run(override); err != nil {
+		return fmt.Errorf("failed to set user agent override: %w", err)
+	}
+	return nil
+}
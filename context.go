@@ -0,0 +1,176 @@
+package osciris
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/chromedp/cdproto/cdp"
+	"github.com/chromedp/cdproto/target"
+	"github.com/chromedp/chromedp"
+	fp "github.com/vitaliitsarov/fingerprint-injector-go"
+)
+
+// BrowserContextOptions опции изолированного browser context
+type BrowserContextOptions struct {
+	// Fingerprint для вкладок контекста (nil - используется fingerprint браузера)
+	Fingerprint *fp.Fingerprint
+
+	// Proxy прокси контекста (nil - используется прокси браузера)
+	Proxy *Proxy
+}
+
+// BrowserContext изолированный browser context (аналог окна инкогнито)
+// Вкладки контекста имеют общие cookies и storage, отделенные от остальных контекстов того же Chrome
+type BrowserContext struct {
+	browser     *Browser
+	id          cdp.BrowserContextID
+	fingerprint *fp.Fingerprint
+	injector    *fp.Injector
+	proxy       *Proxy
+
+	mu     sync.Mutex
+	pages  []*Page
+	closed bool
+}
+
+// NewBrowserContext создает новый browser context в браузере
+// Контекст удаляется через Close; при отключении от удаленного браузера Chrome удаляет его сам
+func (b *Browser) NewBrowserContext(options *BrowserContextOptions) (*BrowserContext, error) {
+	if options == nil {
+		options = &BrowserContextOptions{}
+	}
+
+	params := target.CreateBrowserContext().WithDisposeOnDetach(true)
+	if options.Proxy != nil {
+		params = params.WithProxyServer(options.Proxy.Server())
+		if len(options.Proxy.Bypass) > 0 {
+			params = params.WithProxyBypassList(options.Proxy.BypassList())
+		}
+	}
+
+	var id cdp.BrowserContextID
+	err := b.runBrowser(func(ctx context.Context) error {
+		var err error
+		id, err = params.Do(ctx)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create browser context: %w", err)
+	}
+
+	bc := &BrowserContext{
+		browser: b,
+		id:      id,
+		proxy:   options.Proxy,
+	}
+	if options.Fingerprint != nil {
+		bc.fingerprint = options.Fingerprint
+		bc.injector = fp.NewInjector(options.Fingerprint)
+	}
+
+	return bc, nil
+}
+
+// ID возвращает ID browser context
+func (bc *BrowserContext) ID() cdp.BrowserContextID {
+	return bc.id
+}
+
+// Browser возвращает браузер, в котором создан контекст
+func (bc *BrowserContext) Browser() *Browser {
+	return bc.browser
+}
+
+// NewTab открывает новую вкладку в контексте и возвращает привязанную к ней страницу
+func (bc *BrowserContext) NewTab(url string) (*Page, error) {
+	bc.mu.Lock()
+	closed := bc.closed
+	bc.mu.Unlock()
+	if closed {
+		return nil, fmt.Errorf("browser context %s is closed", bc.id)
+	}
+
+	p := &Page{browser: bc.browser, proxy: bc.proxy, context: bc}
+	return bc.browser.newTab(url, p, chromedp.WithExistingBrowserContext(bc.id))
+}
+
+// Pages возвращает открытые страницы контекста
+func (bc *BrowserContext) Pages() []*Page {
+	bc.mu.Lock()
+	defer bc.mu.Unlock()
+
+	pages := make([]*Page, len(bc.pages))
+	copy(pages, bc.pages)
+	return pages
+}
+
+// ListTabs возвращает все вкладки контекста, включая открытые со страниц (window.open, target=_blank)
+func (bc *BrowserContext) ListTabs() ([]Tab, error) {
+	var infos []*target.Info
+	err := bc.browser.runBrowser(func(ctx context.Context) error {
+		var err error
+		infos, err = target.GetTargets().Do(ctx)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get targets: %w", err)
+	}
+
+	var tabs []Tab
+	for _, info := range infos {
+		if info.Type == "page" && info.BrowserContextID == bc.id {
+			tabs = append(tabs, tabFromInfo(info))
+		}
+	}
+	return tabs, nil
+}
+
+// Close закрывает все вкладки контекста и удаляет его вместе с cookies и storage
+func (bc *BrowserContext) Close() error {
+	bc.mu.Lock()
+	if bc.closed {
+		bc.mu.Unlock()
+		return nil
+	}
+	bc.closed = true
+	pages := bc.pages
+	bc.pages = nil
+	bc.mu.Unlock()
+
+	for _, p := range pages {
+		p.cancel()
+	}
+
+	// DisposeBrowserContext закрывает и вкладки, открытые не через NewTab
+	err := bc.browser.runBrowser(target.DisposeBrowserContext(bc.id).Do)
+	if err != nil {
+		return fmt.Errorf("failed to dispose browser context: %w", err)
+	}
+	return nil
+}
+
+// addPage добавляет страницу в список вкладок контекста; контекст мог закрыться, пока вкладка настраивалась
+func (bc *BrowserContext) addPage(p *Page) error {
+	bc.mu.Lock()
+	defer bc.mu.Unlock()
+
+	if bc.closed {
+		return fmt.Errorf("browser context %s is closed", bc.id)
+	}
+	bc.pages = append(bc.pages, p)
+	return nil
+}
+
+// removePage удаляет страницу из списка вкладок контекста
+func (bc *BrowserContext) removePage(p *Page) {
+	bc.mu.Lock()
+	defer bc.mu.Unlock()
+
+	for i, page := range bc.pages {
+		if page == p {
+			bc.pages = append(bc.pages[:i], bc.pages[i+1:]...)
+			return
+		}
+	}
+}
@@ -0,0 +1,363 @@
+package osciris
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/chromedp/cdproto/cdp"
+	"github.com/chromedp/cdproto/network"
+	"github.com/chromedp/cdproto/storage"
+	"github.com/chromedp/chromedp"
+)
+
+// CookieFormat формат файла cookies
+type CookieFormat string
+
+const (
+	// CookieFormatJSON массив cookies в формате CDP (network.Cookie)
+	CookieFormatJSON CookieFormat = "json"
+	// CookieFormatNetscape формат cookies.txt (curl, wget, yt-dlp)
+	CookieFormatNetscape CookieFormat = "netscape"
+)
+
+// netscapeHTTPOnlyPrefix префикс домена http-only cookie в формате cookies.txt
+const netscapeHTTPOnlyPrefix = "#HttpOnly_"
+
+// CookieFilter условие отбора cookies для удаления
+// Пустые поля не участвуют в проверке; пустой фильтр удаляет все cookies
+type CookieFilter struct {
+	Name string
+	// Domain домен cookie; cookies поддоменов ("sub.example.com" для "example.com") тоже подходят
+	Domain string
+	Path   string
+}
+
+// Cookies возвращает cookies страницы
+// Если urls указаны, возвращает cookies для этих URL, иначе - для текущего URL страницы
+func (p *Page) Cookies(urls ...string) ([]*network.Cookie, error) {
+	var cookies []*network.Cookie
+	err := p.run(chromedp.ActionFunc(func(ctx context.Context) error {
+		params := network.GetCookies()
+		if len(urls) > 0 {
+			params = params.WithURLs(urls)
+		}
+		var err error
+		cookies, err = params.Do(ctx)
+		return err
+	}))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get cookies: %w", err)
+	}
+	return cookies, nil
+}
+
+// SetCookies устанавливает cookies в browser context страницы
+func (p *Page) SetCookies(cookies []*network.CookieParam) error {
+	if err := p.run(network.SetCookies(cookies)); err != nil {
+		return fmt.Errorf("failed to set cookies: %w", err)
+	}
+	return nil
+}
+
+// DeleteCookies удаляет cookies browser context страницы, подходящие под фильтр
+func (p *Page) DeleteCookies(filter CookieFilter) error {
+	// Network.getCookies возвращает только cookies URL страницы, поэтому список берем из Storage domain
+	cookies, err := p.browser.contextCookies(p.browserContextID())
+	if err != nil {
+		return err
+	}
+
+	err = p.run(chromedp.ActionFunc(func(ctx context.Context) error {
+		for _, c := range cookies {
+			if !filter.match(c) {
+				continue
+			}
+			params := network.DeleteCookies(c.Name).WithDomain(c.Domain).WithPath(c.Path)
+			if c.PartitionKey != nil {
+				params = params.WithPartitionKey(c.PartitionKey)
+			}
+			if err := params.Do(ctx); err != nil {
+				return err
+			}
+		}
+		return nil
+	}))
+	if err != nil {
+		return fmt.Errorf("failed to delete cookies: %w", err)
+	}
+	return nil
+}
+
+// browserContextID возвращает ID browser context вкладки страницы (пусто для контекста по умолчанию)
+func (p *Page) browserContextID() cdp.BrowserContextID {
+	if p.context != nil {
+		return p.context.id
+	}
+	if c := chromedp.FromContext(p.ctx); c != nil && c.BrowserContextID != "" {
+		return c.BrowserContextID
+	}
+	return p.browser.browserContextID()
+}
+
+// browserContextID возвращает ID browser context собственной вкладки Browser (пусто для контекста по умолчанию)
+func (b *Browser) browserContextID() cdp.BrowserContextID {
+	// Вкладка удаленного браузера с прокси профиля
+	if c := chromedp.FromContext(b.ctx); c != nil && c.BrowserContextID != "" {
+		return c.BrowserContextID
+	}
+	// Вкладка Browser, открытого через OpenTabWithProfile
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.ownedContext
+}
+
+// ExportCookies записывает все cookies browser context вкладки браузера в w
+func (b *Browser) ExportCookies(w io.Writer, format CookieFormat) error {
+	return b.exportCookies(b.browserContextID(), w, format)
+}
+
+// ImportCookies загружает cookies из r в browser context вкладки браузера
+func (b *Browser) ImportCookies(r io.Reader, format CookieFormat) error {
+	return b.importCookies(b.browserContextID(), r, format)
+}
+
+// ExportCookies записывает все cookies контекста в w
+func (bc *BrowserContext) ExportCookies(w io.Writer, format CookieFormat) error {
+	return bc.browser.exportCookies(bc.id, w, format)
+}
+
+// ImportCookies загружает cookies из r в контекст
+func (bc *BrowserContext) ImportCookies(r io.Reader, format CookieFormat) error {
+	return bc.browser.importCookies(bc.id, r, format)
+}
+
+// exportCookies записывает cookies browser context (пустой ID - контекст по умолчанию)
+func (b *Browser) exportCookies(id cdp.BrowserContextID, w io.Writer, format CookieFormat) error {
+	cookies, err := b.contextCookies(id)
+	if err != nil {
+		return err
+	}
+
+	switch format {
+	case CookieFormatJSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(cookies)
+	case CookieFormatNetscape:
+		return writeNetscapeCookies(w, cookies)
+	default:
+		return fmt.Errorf("unknown cookie format: %q", format)
+	}
+}
+
+// importCookies загружает cookies в browser context (пустой ID - контекст по умолчанию)
+func (b *Browser) importCookies(id cdp.BrowserContextID, r io.Reader, format CookieFormat) error {
+	var cookies []*network.Cookie
+	switch format {
+	case CookieFormatJSON:
+		if err := json.NewDecoder(r).Decode(&cookies); err != nil {
+			return fmt.Errorf("failed to decode cookies: %w", err)
+		}
+	case CookieFormatNetscape:
+		var err error
+		if cookies, err = readNetscapeCookies(r); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("unknown cookie format: %q", format)
+	}
+
+	return b.setContextCookies(id, cookies)
+}
+
+// contextCookies возвращает все cookies browser context (пустой ID - контекст по умолчанию)
+func (b *Browser) contextCookies(id cdp.BrowserContextID) ([]*network.Cookie, error) {
+	var cookies []*network.Cookie
+	err := b.runBrowser(func(ctx context.Context) error {
+		params := storage.GetCookies()
+		if id != "" {
+			params = params.WithBrowserContextID(id)
+		}
+		var err error
+		cookies, err = params.Do(ctx)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get cookies: %w", err)
+	}
+	return cookies, nil
+}
+
+// setContextCookies устанавливает cookies в browser context (пустой ID - контекст по умолчанию)
+func (b *Browser) setContextCookies(id cdp.BrowserContextID, cookies []*network.Cookie) error {
+	if len(cookies) == 0 {
+		return nil
+	}
+
+	params := make([]*network.CookieParam, 0, len(cookies))
+	for _, c := range cookies {
+		params = append(params, cookieParam(c))
+	}
+
+	err := b.runBrowser(func(ctx context.Context) error {
+		action := storage.SetCookies(params)
+		if id != "" {
+			action = action.WithBrowserContextID(id)
+		}
+		return action.Do(ctx)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to set cookies: %w", err)
+	}
+	return nil
+}
+
+// match проверяет cookie на соответствие фильтру
+func (f *CookieFilter) match(c *network.Cookie) bool {
+	if f.Name != "" && f.Name != c.Name {
+		return false
+	}
+	if f.Path != "" && f.Path != c.Path {
+		return false
+	}
+	if f.Domain != "" {
+		domain := strings.TrimPrefix(c.Domain, ".")
+		want := strings.TrimPrefix(f.Domain, ".")
+		if domain != want && !strings.HasSuffix(domain, "."+want) {
+			return false
+		}
+	}
+	return true
+}
+
+// cookieParam преобразует полученную cookie в параметр для установки
+// Host-only cookie (домен без точки) устанавливается через URL: с Domain Chrome сделал бы ее доступной поддоменам
+func cookieParam(c *network.Cookie) *network.CookieParam {
+	param := &network.CookieParam{
+		Name:         c.Name,
+		Value:        c.Value,
+		Domain:       c.Domain,
+		Path:         c.Path,
+		Secure:       c.Secure,
+		HTTPOnly:     c.HTTPOnly,
+		SameSite:     c.SameSite,
+		Priority:     c.Priority,
+		SourceScheme: c.SourceScheme,
+		PartitionKey: c.PartitionKey,
+	}
+	if c.Domain != "" && !strings.HasPrefix(c.Domain, ".") {
+		scheme := "http"
+		if c.Secure {
+			scheme = "https"
+		}
+		param.URL = scheme + "://" + c.Domain + c.Path
+		param.Domain = ""
+	}
+	if c.SourcePort > 0 {
+		param.SourcePort = c.SourcePort
+	}
+	if !c.Session && c.Expires > 0 {
+		expires := cdp.TimeSinceEpoch(unixSeconds(c.Expires))
+		param.Expires = &expires
+	}
+	return param
+}
+
+// writeNetscapeCookies записывает cookies в формате cookies.txt
+func writeNetscapeCookies(w io.Writer, cookies []*network.Cookie) error {
+	bw := bufio.NewWriter(w)
+	fmt.Fprintln(bw, "# Netscape HTTP Cookie File")
+
+	for _, c := range cookies {
+		domain := c.Domain
+		if c.HTTPOnly {
+			domain = netscapeHTTPOnlyPrefix + domain
+		}
+		var expires int64
+		if !c.Session {
+			expires = int64(c.Expires)
+		}
+		fmt.Fprintf(bw, "%s\t%s\t%s\t%s\t%d\t%s\t%s\n",
+			domain,
+			netscapeBool(strings.HasPrefix(c.Domain, ".")),
+			c.Path,
+			netscapeBool(c.Secure),
+			expires,
+			c.Name,
+			c.Value,
+		)
+	}
+
+	return bw.Flush()
+}
+
+// readNetscapeCookies читает cookies в формате cookies.txt
+func readNetscapeCookies(r io.Reader) ([]*network.Cookie, error) {
+	var cookies []*network.Cookie
+
+	scanner := bufio.NewScanner(r)
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := strings.TrimRight(scanner.Text(), "\r")
+
+		httpOnly := strings.HasPrefix(text, netscapeHTTPOnlyPrefix)
+		if httpOnly {
+			text = strings.TrimPrefix(text, netscapeHTTPOnlyPrefix)
+		}
+		if strings.TrimSpace(text) == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+
+		fields := strings.Split(text, "\t")
+		if len(fields) != 7 {
+			return nil, fmt.Errorf("invalid cookies.txt line %d: expected 7 fields, got %d", line, len(fields))
+		}
+		expires, err := strconv.ParseInt(fields[4], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid cookies.txt line %d: invalid expires %q", line, fields[4])
+		}
+
+		// Домен без точки (include subdomains FALSE) означает host-only cookie
+		domain := fields[0]
+		if strings.EqualFold(fields[1], "TRUE") && !strings.HasPrefix(domain, ".") {
+			domain = "." + domain
+		}
+		cookies = append(cookies, &network.Cookie{
+			Domain:   domain,
+			Path:     fields[2],
+			Secure:   strings.EqualFold(fields[3], "TRUE"),
+			Expires:  float64(expires),
+			Session:  expires == 0,
+			Name:     fields[5],
+			Value:    fields[6],
+			HTTPOnly: httpOnly,
+		})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read cookies: %w", err)
+	}
+
+	return cookies, nil
+}
+
+// netscapeBool возвращает логическое значение в формате cookies.txt
+func netscapeBool(v bool) string {
+	if v {
+		return "TRUE"
+	}
+	return "FALSE"
+}
+
+// unixSeconds преобразует секунды с начала эпохи UNIX в time.Time
+func unixSeconds(seconds float64) time.Time {
+	sec, frac := math.Modf(seconds)
+	return time.Unix(int64(sec), int64(frac*float64(time.Second)))
+}
@@ -0,0 +1,312 @@
+package osciris
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"time"
+
+	"github.com/chromedp/cdproto/cdp"
+	"github.com/chromedp/cdproto/dom"
+	"github.com/chromedp/cdproto/emulation"
+	"github.com/chromedp/cdproto/input"
+	"github.com/chromedp/cdproto/page"
+	"github.com/chromedp/chromedp"
+	fp "github.com/vitaliitsarov/fingerprint-injector-go"
+)
+
+// Device параметры эмулируемого устройства
+// Размеры указываются в CSS пикселях для портретной ориентации
+type Device struct {
+	Name              string
+	Width             int64
+	Height            int64
+	DeviceScaleFactor float64
+	Mobile            bool
+	// Touch включает сенсорный ввод: click-хелперы страницы отправляют touch события
+	Touch          bool
+	MaxTouchPoints int64
+	// Landscape альбомная ориентация (ширина и высота меняются местами)
+	Landscape bool
+
+	UserAgent string
+	Platform  string
+	Vendor    string
+	// Model и PlatformVersion client hints: User-Agent Android скрывает их ("Android 10; K")
+	Model           string
+	PlatformVersion string
+
+	// Значения для Fingerprint()
+	HardwareConcurrency int
+	DeviceMemory        int
+	WebGLVendor         string
+	WebGLRenderer       string
+}
+
+const (
+	iOSUserAgent     = "Mozilla/5.0 (iPhone; CPU iPhone OS 17_5 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/17.5 Mobile/15E148 Safari/604.1"
+	iPadUserAgent    = "Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/17.5 Safari/605.1.15"
+	androidUserAgent = "Mozilla/5.0 (Linux; Android 10; K) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/119.0.0.0 Mobile Safari/537.36"
+	androidTabletUA  = "Mozilla/5.0 (Linux; Android 10; K) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/119.0.0.0 Safari/537.36"
+)
+
+// Пресеты устройств
+var (
+	DeviceIPhone15Pro = Device{
+		Name: "iPhone 15 Pro", Width: 393, Height: 852, DeviceScaleFactor: 3, Mobile: true, Touch: true, MaxTouchPoints: 5,
+		UserAgent: iOSUserAgent, Platform: "iPhone", Vendor: "Apple Computer, Inc.",
+		HardwareConcurrency: 6, WebGLVendor: "Apple Inc.", WebGLRenderer: "Apple GPU",
+	}
+	DeviceIPhoneSE = Device{
+		Name: "iPhone SE", Width: 375, Height: 667, DeviceScaleFactor: 2, Mobile: true, Touch: true, MaxTouchPoints: 5,
+		UserAgent: iOSUserAgent, Platform: "iPhone", Vendor: "Apple Computer, Inc.",
+		HardwareConcurrency: 6, WebGLVendor: "Apple Inc.", WebGLRenderer: "Apple GPU",
+	}
+	DevicePixel7 = Device{
+		Name: "Pixel 7", Width: 412, Height: 915, DeviceScaleFactor: 2.625, Mobile: true, Touch: true, MaxTouchPoints: 5,
+		UserAgent: androidUserAgent, Platform: "Linux armv8l", Vendor: "Google Inc.",
+		Model: "Pixel 7", PlatformVersion: "14.0.0",
+		HardwareConcurrency: 8, DeviceMemory: 8, WebGLVendor: "ARM", WebGLRenderer: "Mali-G710",
+	}
+	DeviceGalaxyS23 = Device{
+		Name: "Galaxy S23", Width: 360, Height: 780, DeviceScaleFactor: 3, Mobile: true, Touch: true, MaxTouchPoints: 5,
+		UserAgent: androidUserAgent, Platform: "Linux armv8l", Vendor: "Google Inc.",
+		Model: "SM-S911B", PlatformVersion: "14.0.0",
+		HardwareConcurrency: 8, DeviceMemory: 8, WebGLVendor: "Qualcomm", WebGLRenderer: "Adreno (TM) 740",
+	}
+	DeviceIPadAir = Device{
+		Name: "iPad Air", Width: 820, Height: 1180, DeviceScaleFactor: 2, Mobile: true, Touch: true, MaxTouchPoints: 5,
+		UserAgent: iPadUserAgent, Platform: "MacIntel", Vendor: "Apple Computer, Inc.",
+		HardwareConcurrency: 8, WebGLVendor: "Apple Inc.", WebGLRenderer: "Apple GPU",
+	}
+	DeviceIPadPro11 = Device{
+		Name: "iPad Pro 11", Width: 834, Height: 1194, DeviceScaleFactor: 2, Mobile: true, Touch: true, MaxTouchPoints: 5,
+		UserAgent: iPadUserAgent, Platform: "MacIntel", Vendor: "Apple Computer, Inc.",
+		HardwareConcurrency: 8, WebGLVendor: "Apple Inc.", WebGLRenderer: "Apple GPU",
+	}
+	DevicePixelTablet = Device{
+		Name: "Pixel Tablet", Width: 800, Height: 1280, DeviceScaleFactor: 2, Mobile: true, Touch: true, MaxTouchPoints: 10,
+		UserAgent: androidTabletUA, Platform: "Linux armv8l", Vendor: "Google Inc.",
+		Model: "Pixel Tablet", PlatformVersion: "14.0.0",
+		HardwareConcurrency: 8, DeviceMemory: 8, WebGLVendor: "ARM", WebGLRenderer: "Mali-G710",
+	}
+)
+
+// Devices все пресеты устройств
+var Devices = []Device{
+	DeviceIPhone15Pro,
+	DeviceIPhoneSE,
+	DevicePixel7,
+	DeviceGalaxyS23,
+	DeviceIPadAir,
+	DeviceIPadPro11,
+	DevicePixelTablet,
+}
+
+// size возвращает размеры с учетом ориентации
+func (d Device) size() (width, height int64) {
+	if d.Landscape {
+		return d.Height, d.Width
+	}
+	return d.Width, d.Height
+}
+
+// Fingerprint возвращает fingerprint устройства для BrowserOptions.Fingerprint,
+// чтобы подмена navigator и screen не противоречила эмуляции через EmulateDevice
+func (d Device) Fingerprint() *fp.Fingerprint {
+	width, height := d.size()
+	f := &fp.Fingerprint{
+		UserAgent: d.UserAgent,
+		Platform:  d.Platform,
+		Vendor:    d.Vendor,
+		Language:  "en-US",
+		Languages: []string{"en-US", "en"},
+		Screen: &fp.Screen{
+			Width:            int(width),
+			Height:           int(height),
+			AvailWidth:       int(width),
+			AvailHeight:      int(height),
+			ColorDepth:       24,
+			PixelDepth:       24,
+			DevicePixelRatio: d.DeviceScaleFactor,
+		},
+		HardwareConcurrency: d.HardwareConcurrency,
+		DeviceMemory:        d.DeviceMemory,
+	}
+	if d.WebGLRenderer != "" {
+		f.WebGL = &fp.WebGL{Vendor: d.WebGLVendor, Renderer: d.WebGLRenderer}
+	}
+	return f
+}
+
+// userAgentMetadata возвращает client hints устройства (nil для User-Agent Safari)
+func (d Device) userAgentMetadata() *emulation.UserAgentMetadata {
+	md := userAgentMetadata(d.UserAgent)
+	if md == nil {
+		return nil
+	}
+	if d.Model != "" {
+		md.Model = d.Model
+	}
+	if d.PlatformVersion != "" {
+		md.PlatformVersion = d.PlatformVersion
+	}
+	return md
+}
+
+// EmulateDevice эмулирует устройство во вкладке страницы: viewport, DPR, ориентацию экрана,
+// сенсорный ввод, User-Agent и client hints
+// Для устройств с User-Agent Safari navigator.userAgentData скрывается, как в настоящем Safari
+// После включения Touch click-хелперы (Click, ClickWithScroll, ClickXY, MouseClick левой кнопкой) отправляют touch события
+func (p *Page) EmulateDevice(d Device) error {
+	width, height := d.size()
+	orientation := &emulation.ScreenOrientation{Type: emulation.OrientationTypePortraitPrimary}
+	if d.Landscape {
+		orientation = &emulation.ScreenOrientation{Type: emulation.OrientationTypeLandscapePrimary, Angle: 90}
+	}
+	scale := d.DeviceScaleFactor
+	if scale == 0 {
+		scale = 1
+	}
+
+	actions := []chromedp.Action{
+		chromedp.ActionFunc(func(ctx context.Context) error {
+			if err := emulation.SetDeviceMetricsOverride(width, height, scale, d.Mobile).
+				WithScreenWidth(width).
+				WithScreenHeight(height).
+				WithScreenOrientation(orientation).
+				Do(ctx); err != nil {
+				return err
+			}
+			touch := emulation.SetTouchEmulationEnabled(d.Touch)
+			if d.Touch && d.MaxTouchPoints > 0 {
+				touch = touch.WithMaxTouchPoints(d.MaxTouchPoints)
+			}
+			return touch.Do(ctx)
+		}),
+	}
+	if d.UserAgent != "" {
+		md := d.userAgentMetadata()
+		actions = append(actions,
+			userAgentMetadataOverride(d.UserAgent, d.Platform, p.browser.pageEmulation(p).acceptLanguage(), md),
+			p.hideUserAgentData(md == nil),
+		)
+	}
+
+	if err := p.run(actions...); err != nil {
+		return fmt.Errorf("failed to emulate device %s: %w", d.Name, err)
+	}
+
+	p.mu.Lock()
+	p.device = &d
+	p.mu.Unlock()
+	return nil
+}
+
+// hideUserAgentDataScript удаляет navigator.userAgentData, которого нет в Safari
+const hideUserAgentDataScript = `delete Object.getPrototypeOf(navigator).userAgentData`
+
+// hideUserAgentData скрывает navigator.userAgentData в новых документах и текущем или снимает скрытие
+// Снятие действует с перезагрузки страницы
+func (p *Page) hideUserAgentData(hide bool) chromedp.ActionFunc {
+	return func(ctx context.Context) error {
+		p.mu.Lock()
+		id := p.userAgentDataScript
+		p.userAgentDataScript = ""
+		p.mu.Unlock()
+
+		if id != "" {
+			if err := page.RemoveScriptToEvaluateOnNewDocument(id).Do(ctx); err != nil {
+				return err
+			}
+		}
+		if !hide {
+			return nil
+		}
+
+		id, err := page.AddScriptToEvaluateOnNewDocument(hideUserAgentDataScript).Do(ctx)
+		if err != nil {
+			return err
+		}
+		p.mu.Lock()
+		p.userAgentDataScript = id
+		p.mu.Unlock()
+		return chromedp.Evaluate(hideUserAgentDataScript, nil).Do(ctx)
+	}
+}
+
+// touch возвращает true, если страница эмулирует сенсорное устройство
+func (p *Page) touch() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.device != nil && p.device.Touch
+}
+
+// nodeQuad прокручивает к первому видимому элементу и возвращает его content quad
+func nodeQuad(ctx context.Context, selector string) (dom.Quad, error) {
+	var nodes []*cdp.Node
+	if err := chromedp.Nodes(selector, &nodes, chromedp.NodeVisible).Do(ctx); err != nil {
+		return nil, err
+	}
+	if len(nodes) == 0 {
+		return nil, fmt.Errorf("element not found: %s", selector)
+	}
+
+	if err := dom.ScrollIntoViewIfNeeded().WithNodeID(nodes[0].NodeID).Do(ctx); err != nil {
+		return nil, err
+	}
+	quads, err := dom.GetContentQuads().WithNodeID(nodes[0].NodeID).Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if len(quads) == 0 || len(quads[0]) < 8 {
+		return nil, fmt.Errorf("element has no layout: %s", selector)
+	}
+	return quads[0], nil
+}
+
+// nodeCenter прокручивает к первому видимому элементу и возвращает координаты его центра
+func nodeCenter(ctx context.Context, selector string) (x, y float64, err error) {
+	q, err := nodeQuad(ctx, selector)
+	if err != nil {
+		return 0, 0, err
+	}
+	return (q[0] + q[2] + q[4] + q[6]) / 4, (q[1] + q[3] + q[5] + q[7]) / 4, nil
+}
+
+// dispatchTap касается точки и отпускает палец через hold
+func dispatchTap(ctx context.Context, x, y float64, hold time.Duration) (err error) {
+	points := []*input.TouchPoint{{X: x, Y: y}}
+	if err := input.DispatchTouchEvent(input.TouchStart, points).Do(ctx); err != nil {
+		return err
+	}
+	defer func() {
+		if endErr := releaseTouch(ctx); err == nil {
+			err = endErr
+		}
+	}()
+	return sleep(ctx, hold)
+}
+
+// releaseTouch отпускает палец и после отмены ctx, чтобы касание не осталось активным на странице
+func releaseTouch(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), releaseTimeout)
+	defer cancel()
+	return input.DispatchTouchEvent(input.TouchEnd, []*input.TouchPoint{}).Do(ctx)
+}
+
+// tapDuration длительность касания при клике (50-120ms)
+func tapDuration() time.Duration {
+	return time.Duration(50+rand.Intn(70)) * time.Millisecond
+}
+
+// tapSelector касается центра элемента
+func tapSelector(selector string) chromedp.ActionFunc {
+	return func(ctx context.Context) error {
+		x, y, err := nodeCenter(ctx, selector)
+		if err != nil {
+			return err
+		}
+		return dispatchTap(ctx, x, y, tapDuration())
+	}
+}
@@ -0,0 +1,266 @@
+package osciris
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/chromedp/cdproto"
+	"github.com/chromedp/cdproto/target"
+	"github.com/chromedp/chromedp"
+	jsonv2 "github.com/go-json-experiment/json"
+)
+
+// devtoolsPrefix начало строки вывода Chrome с адресом DevTools
+var devtoolsPrefix = []byte("DevTools listening on")
+
+// devtoolsEndpoint запоминает адрес DevTools из вывода локального Chrome (chromedp.CombinedOutput)
+type devtoolsEndpoint struct {
+	mu  sync.Mutex
+	url string
+}
+
+// devtoolsConn собственное CDP-соединение Browser для flat-сессий дочерних целей
+// chromedp отбрасывает сообщения сессий, к которым подключался не он, поэтому такие сессии обслуживаются отдельно
+type devtoolsConn struct {
+	conn *chromedp.Conn
+	next int64
+
+	writeMu sync.Mutex
+
+	mu        sync.Mutex
+	pending   map[int64]chan *cdproto.Message
+	listeners map[target.SessionID]func(ev any)
+	done      chan struct{}
+}
+
+// devtoolsSession flat-сессия собственного соединения (пустой id - сессия браузера); реализует cdp.Executor
+type devtoolsSession struct {
+	conn *devtoolsConn
+	id   target.SessionID
+}
+
+// Write получает вывод Chrome; до появления адреса chromedp передает вывод построчно
+func (e *devtoolsEndpoint) Write(p []byte) (int, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.url == "" && bytes.HasPrefix(p, devtoolsPrefix) {
+		e.url = string(bytes.TrimSpace(p[len(devtoolsPrefix):]))
+	}
+	return len(p), nil
+}
+
+// devtoolsConn возвращает собственное CDP-соединение Browser, подключаясь при первом обращении
+func (b *Browser) devtoolsConn() (*devtoolsConn, error) {
+	b.sessionMu.Lock()
+	defer b.sessionMu.Unlock()
+
+	if b.devtools != nil {
+		return b.devtools, nil
+	}
+
+	ctx, cancel := context.WithTimeout(b.ctx, b.options.Timeout)
+	defer cancel()
+
+	wsURL, err := b.devtoolsURL(ctx)
+	if err != nil {
+		return nil, err
+	}
+	conn, err := chromedp.DialContext(ctx, wsURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to browser: %w", err)
+	}
+
+	b.devtools = &devtoolsConn{
+		conn:      conn,
+		pending:   make(map[int64]chan *cdproto.Message),
+		listeners: make(map[target.SessionID]func(ev any)),
+		done:      make(chan struct{}),
+	}
+	go b.devtools.read()
+	return b.devtools, nil
+}
+
+// devtoolsURL возвращает websocket адрес DevTools браузера
+func (b *Browser) devtoolsURL(ctx context.Context) (string, error) {
+	if b.isRemote {
+		return resolveDevtoolsURL(ctx, b.options.RemoteURL)
+	}
+
+	var wsURL string
+	if e := b.endpoint; e != nil {
+		e.mu.Lock()
+		wsURL = e.url
+		e.mu.Unlock()
+	}
+	if wsURL == "" {
+		return "", fmt.Errorf("devtools address of the local browser is unknown")
+	}
+	return wsURL, nil
+}
+
+// resolveDevtoolsURL получает websocket адрес браузера из RemoteURL так же, как chromedp.NewRemoteAllocator
+// Chrome принимает в заголовке Host только IP или localhost, поэтому имя хоста заменяется на IP
+func resolveDevtoolsURL(ctx context.Context, remoteURL string) (string, error) {
+	u, err := url.Parse(remoteURL)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse remote url: %w", err)
+	}
+	host, port, err := net.SplitHostPort(u.Host)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse remote url: %w", err)
+	}
+	if host != "localhost" && net.ParseIP(host) == nil {
+		addrs, err := net.DefaultResolver.LookupIPAddr(ctx, host)
+		if err != nil {
+			return "", fmt.Errorf("failed to resolve remote host: %w", err)
+		}
+		host = addrs[0].IP.String()
+	}
+	u.Host = net.JoinHostPort(host, port)
+
+	if strings.Contains(u.Path, "/devtools/browser/") {
+		return u.String(), nil
+	}
+
+	u.Scheme = "http"
+	u.Path = "/json/version"
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return "", err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to get browser version: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var version struct {
+		WebSocketDebuggerURL string `json:"webSocketDebuggerUrl"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&version); err != nil {
+		return "", fmt.Errorf("failed to decode browser version: %w", err)
+	}
+	if version.WebSocketDebuggerURL == "" {
+		return "", fmt.Errorf("browser version has no webSocketDebuggerUrl")
+	}
+	return version.WebSocketDebuggerURL, nil
+}
+
+// read читает сообщения соединения до его закрытия: ответы передаются командам, события - обработчикам сессий
+func (c *devtoolsConn) read() {
+	defer close(c.done)
+
+	for {
+		msg := new(cdproto.Message)
+		if err := c.conn.Read(context.Background(), msg); err != nil {
+			return
+		}
+
+		if msg.ID != 0 {
+			c.mu.Lock()
+			ch := c.pending[msg.ID]
+			delete(c.pending, msg.ID)
+			c.mu.Unlock()
+			if ch != nil {
+				ch <- msg
+			}
+			continue
+		}
+		if msg.Method == "" {
+			continue
+		}
+
+		c.mu.Lock()
+		listener := c.listeners[msg.SessionID]
+		c.mu.Unlock()
+		if listener == nil {
+			continue
+		}
+		if ev, err := cdproto.UnmarshalMessage(msg, chromedp.DefaultUnmarshalOptions); err == nil {
+			listener(ev)
+		}
+	}
+}
+
+// listen передает события сессии id обработчику fn (nil - перестать передавать)
+// Обработчик вызывается в горутине чтения и не должен ждать ответов на команды
+func (c *devtoolsConn) listen(id target.SessionID, fn func(ev any)) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if fn == nil {
+		delete(c.listeners, id)
+		return
+	}
+	c.listeners[id] = fn
+}
+
+// session возвращает executor flat-сессии id
+func (c *devtoolsConn) session(id target.SessionID) *devtoolsSession {
+	return &devtoolsSession{conn: c, id: id}
+}
+
+// Close закрывает соединение; ожидающие команды завершаются с ошибкой
+func (c *devtoolsConn) Close() error {
+	return c.conn.Close()
+}
+
+// Execute отправляет команду в сессию и ждет ответа
+func (s *devtoolsSession) Execute(ctx context.Context, method string, params, res any) error {
+	c := s.conn
+	id := atomic.AddInt64(&c.next, 1)
+
+	var buf []byte
+	if params != nil {
+		var err error
+		if buf, err = jsonv2.Marshal(params, chromedp.DefaultMarshalOptions); err != nil {
+			return err
+		}
+	}
+
+	ch := make(chan *cdproto.Message, 1)
+	c.mu.Lock()
+	c.pending[id] = ch
+	c.mu.Unlock()
+	defer func() {
+		c.mu.Lock()
+		delete(c.pending, id)
+		c.mu.Unlock()
+	}()
+
+	c.writeMu.Lock()
+	err := c.conn.Write(ctx, &cdproto.Message{
+		ID:        id,
+		SessionID: s.id,
+		Method:    cdproto.MethodType(method),
+		Params:    buf,
+	})
+	c.writeMu.Unlock()
+	if err != nil {
+		return err
+	}
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-c.done:
+		return chromedp.ErrChannelClosed
+	case msg := <-ch:
+		switch {
+		case msg.Error != nil:
+			return msg.Error
+		case res != nil:
+			return jsonv2.Unmarshal(msg.Result, res, chromedp.DefaultUnmarshalOptions)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,163 @@
+package osciris
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/chromedp/cdproto/browser"
+	"github.com/chromedp/cdproto/emulation"
+	"github.com/chromedp/chromedp"
+)
+
+// pageEmulation часовой пояс, локаль, языки и геолокация вкладки; профиль имеет приоритет над fingerprint
+type pageEmulation struct {
+	Timezone    string
+	Locale      string
+	Languages   []string
+	Geolocation *Geolocation
+}
+
+// pageEmulation собирает параметры эмуляции вкладки страницы (nil - эмулировать нечего)
+func (b *Browser) pageEmulation(p *Page) *pageEmulation {
+	e := &pageEmulation{Geolocation: b.options.Geolocation}
+
+	if f, _ := b.pageFingerprint(p); f != nil {
+		if f.Timezone != nil {
+			e.Timezone = f.Timezone.ID
+		}
+		e.Locale = f.Language
+		e.Languages = append(e.Languages, f.Languages...)
+		if e.Locale == "" && len(e.Languages) > 0 {
+			e.Locale = e.Languages[0]
+		}
+	}
+
+	if profile := b.options.Profile; profile != nil {
+		if profile.Timezone != "" {
+			e.Timezone = profile.Timezone
+		}
+		if profile.Locale != "" {
+			e.Locale = profile.Locale
+		}
+		if profile.Geolocation != nil {
+			e.Geolocation = profile.Geolocation
+		}
+	}
+
+	// Accept-Language начинается с языка локали, как и у настоящего браузера
+	if e.Locale != "" && (len(e.Languages) == 0 || e.Languages[0] != e.Locale) {
+		languages := []string{e.Locale}
+		for _, language := range e.Languages {
+			if language != e.Locale {
+				languages = append(languages, language)
+			}
+		}
+		e.Languages = languages
+	}
+
+	if e.Timezone == "" && e.Locale == "" && e.Geolocation == nil {
+		return nil
+	}
+	return e
+}
+
+// acceptLanguage возвращает заголовок Accept-Language в формате Chrome: "de-DE,de;q=0.9,en;q=0.8"
+func (e *pageEmulation) acceptLanguage() string {
+	if e == nil {
+		return ""
+	}
+	languages := expandLanguages(e.Languages)
+	parts := make([]string, 0, len(languages))
+	for i, language := range languages {
+		if i == 0 {
+			parts = append(parts, language)
+			continue
+		}
+		q := 10 - i
+		if q < 1 {
+			q = 1
+		}
+		parts = append(parts, fmt.Sprintf("%s;q=0.%d", language, q))
+	}
+	return strings.Join(parts, ",")
+}
+
+// expandLanguages добавляет базовый язык после языков с регионом, как Chrome: de-DE,en-US -> de-DE,de,en-US,en
+func expandLanguages(languages []string) []string {
+	base := func(language string) string {
+		b, _, _ := strings.Cut(language, "-")
+		return b
+	}
+	seen := make(map[string]bool, len(languages))
+	expanded := make([]string, 0, len(languages)*2)
+	add := func(language string) {
+		if !seen[language] {
+			seen[language] = true
+			expanded = append(expanded, language)
+		}
+	}
+	for i, language := range languages {
+		add(language)
+		// Базовый язык идет после всех подряд идущих вариантов этого языка
+		if b := base(language); b != language && (i+1 == len(languages) || base(languages[i+1]) != b) {
+			add(b)
+		}
+	}
+	return expanded
+}
+
+// overrides эмулирует геолокацию, часовой пояс и локаль в текущей цели
+func (e *pageEmulation) overrides() chromedp.ActionFunc {
+	return func(ctx context.Context) error {
+		if g := e.Geolocation; g != nil {
+			params := emulation.SetGeolocationOverride().
+				WithLatitude(g.Latitude).
+				WithLongitude(g.Longitude)
+			if g.Accuracy > 0 {
+				params = params.WithAccuracy(g.Accuracy)
+			}
+			if err := params.Do(ctx); err != nil {
+				return err
+			}
+		}
+		if e.Timezone != "" {
+			if err := emulation.SetTimezoneOverride(e.Timezone).Do(ctx); err != nil {
+				return err
+			}
+		}
+		if e.Locale != "" {
+			if err := emulation.SetLocaleOverride().WithLocale(e.Locale).Do(ctx); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+// applyEmulation эмулирует часовой пояс, локаль и геолокацию во вкладке страницы
+func (b *Browser) applyEmulation(p *Page) error {
+	e := b.pageEmulation(p)
+	if e == nil {
+		return nil
+	}
+
+	if e.Geolocation != nil {
+		// Без разрешения navigator.geolocation ждет ответа пользователя
+		err := b.runBrowser(func(ctx context.Context) error {
+			grant := browser.GrantPermissions([]browser.PermissionType{browser.PermissionTypeGeolocation})
+			if id := p.browserContextID(); id != "" {
+				grant = grant.WithBrowserContextID(id)
+			}
+			return grant.Do(ctx)
+		})
+		if err != nil {
+			return fmt.Errorf("failed to grant geolocation permission: %w", err)
+		}
+	}
+
+	if err := p.run(e.overrides()); err != nil {
+		return fmt.Errorf("failed to apply emulation: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,339 @@
+package osciris
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/chromedp/cdproto/page"
+	"github.com/chromedp/cdproto/target"
+	"github.com/chromedp/chromedp"
+)
+
+// TabEventType тип события жизненного цикла вкладки
+type TabEventType string
+
+const (
+	// TabCreated вкладка создана
+	TabCreated TabEventType = "created"
+	// TabNavigated у вкладки изменился URL
+	TabNavigated TabEventType = "navigated"
+	// TabClosed вкладка закрыта
+	TabClosed TabEventType = "closed"
+)
+
+// TabEvent событие жизненного цикла вкладки
+type TabEvent struct {
+	Type TabEventType
+	// Tab состояние вкладки на момент события (для TabClosed - последнее известное)
+	Tab Tab
+}
+
+// TabEvents подписывается на события вкладок браузера
+// Канал закрывается после отмены ctx или закрытия Browser
+func (b *Browser) TabEvents(ctx context.Context) (<-chan TabEvent, error) {
+	sessionCtx, err := b.browserSession()
+	if err != nil {
+		return nil, fmt.Errorf("failed to subscribe to tab events: %w", err)
+	}
+
+	// Запоминаем уже существующие вкладки, чтобы не сообщать о них как о новых
+	var targets []*target.Info
+	err = b.runBrowser(func(ctx context.Context) error {
+		var err error
+		targets, err = target.GetTargets().Do(ctx)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get targets: %w", err)
+	}
+
+	known := make(map[target.ID]Tab)
+	for _, t := range targets {
+		if t.Type == "page" {
+			known[t.TargetID] = tabFromInfo(t)
+		}
+	}
+
+	lctx, cancel := context.WithCancel(sessionCtx)
+	go func() {
+		select {
+		case <-ctx.Done():
+		case <-lctx.Done():
+		}
+		cancel()
+	}()
+
+	queue := newTabEventQueue()
+
+	// Обработчик вызывается синхронно из цикла событий chromedp, поэтому не должен блокироваться
+	chromedp.ListenBrowser(lctx, func(ev any) {
+		switch ev := ev.(type) {
+		case *target.EventTargetCreated:
+			if ev.TargetInfo.Type != "page" {
+				return
+			}
+			if _, ok := known[ev.TargetInfo.TargetID]; ok {
+				return
+			}
+			tab := tabFromInfo(ev.TargetInfo)
+			known[tab.ID] = tab
+			queue.push(TabEvent{Type: TabCreated, Tab: tab})
+		case *target.EventTargetInfoChanged:
+			if ev.TargetInfo.Type != "page" {
+				return
+			}
+			tab := tabFromInfo(ev.TargetInfo)
+			prev, ok := known[tab.ID]
+			known[tab.ID] = tab
+			if !ok {
+				queue.push(TabEvent{Type: TabCreated, Tab: tab})
+				return
+			}
+			if prev.URL != tab.URL {
+				queue.push(TabEvent{Type: TabNavigated, Tab: tab})
+			}
+		case *target.EventTargetDestroyed:
+			tab, ok := known[ev.TargetID]
+			if !ok {
+				return
+			}
+			delete(known, ev.TargetID)
+			queue.push(TabEvent{Type: TabClosed, Tab: tab})
+		}
+	})
+
+	// Включаем получение событий targetCreated/targetInfoChanged/targetDestroyed
+	err = b.runBrowser(func(ctx context.Context) error {
+		return target.SetDiscoverTargets(true).Do(ctx)
+	})
+	if err != nil {
+		cancel()
+		return nil, fmt.Errorf("failed to discover targets: %w", err)
+	}
+
+	events := make(chan TabEvent)
+	go queue.pump(lctx, events)
+
+	return events, nil
+}
+
+// WaitForTab ждет создания вкладки, удовлетворяющей условию match
+// Если match равен nil, возвращается первая созданная вкладка
+func (b *Browser) WaitForTab(match func(Tab) bool, timeout time.Duration) (Tab, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	events, err := b.TabEvents(ctx)
+	if err != nil {
+		return Tab{}, err
+	}
+
+	return waitForTab(ctx, events, match)
+}
+
+// WaitForNewTab выполняет действие open (например, Ctrl+Click) и ждет вкладку, которую оно открыло
+// Учитываются только вкладки, открытые из текущей страницы; остальные игнорируются
+// Возвращает страницу новой вкладки после фиксации ее первой навигации и информацию о вкладке
+// URL вкладки в target info может быть адресом еще не зафиксированной навигации
+func (p *Page) WaitForNewTab(open func() error, timeout time.Duration) (*Page, Tab, error) {
+	// Подключаемся к вкладке страницы, чтобы знать ее target ID
+	if err := p.run(); err != nil {
+		return nil, Tab{}, err
+	}
+	openerID := p.TargetID()
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	// Подписываемся до действия, чтобы не пропустить создание вкладки
+	events, err := p.browser.TabEvents(ctx)
+	if err != nil {
+		return nil, Tab{}, err
+	}
+
+	if err := open(); err != nil {
+		return nil, Tab{}, err
+	}
+
+	tab, err := waitForTab(ctx, events, func(t Tab) bool {
+		return t.OpenerID == openerID
+	})
+	if err != nil {
+		return nil, Tab{}, err
+	}
+
+	newPage, err := p.browser.AttachTab(tab.ID)
+	if err != nil {
+		return nil, tab, err
+	}
+	if err := newPage.waitFirstNavigation(ctx, events, tab); err != nil {
+		// Ошибку игнорируем: вкладка могла закрыться сама
+		newPage.Close()
+		return nil, tab, err
+	}
+
+	return newPage, tab, nil
+}
+
+// waitFirstNavigation ждет фиксации навигации в главном фрейме вкладки (Page.frameNavigated)
+// Вкладка, открытая без URL (about:blank), навигации не ждет
+func (p *Page) waitFirstNavigation(ctx context.Context, events <-chan TabEvent, tab Tab) error {
+	committed := make(chan struct{})
+	var once sync.Once
+	lctx, cancel := context.WithCancel(p.ctx)
+	defer cancel()
+	chromedp.ListenTarget(lctx, func(ev any) {
+		if ev, ok := ev.(*page.EventFrameNavigated); ok && ev.Frame.ParentID == "" {
+			once.Do(func() { close(committed) })
+		}
+	})
+
+	// Навигация могла зафиксироваться до подписки: URL главного фрейма меняется только при фиксации
+	var tree *page.FrameTree
+	err := p.run(chromedp.ActionFunc(func(ctx context.Context) error {
+		var err error
+		tree, err = page.GetFrameTree().Do(ctx)
+		return err
+	}))
+	if err != nil {
+		return fmt.Errorf("failed to get frame tree of tab %s: %w", tab.ID, err)
+	}
+	// Учитываем изменения URL вкладки, пришедшие во время подключения
+	for pending := true; pending; {
+		select {
+		case ev, ok := <-events:
+			if !ok {
+				return fmt.Errorf("tab events stream closed")
+			}
+			if ev.Tab.ID == tab.ID && ev.Type == TabNavigated {
+				tab = ev.Tab
+			}
+		default:
+			pending = false
+		}
+	}
+	if !blankURL(tree.Frame.URL) || blankURL(tab.URL) {
+		return nil
+	}
+
+	for {
+		select {
+		case <-committed:
+			return nil
+		case <-ctx.Done():
+			return fmt.Errorf("timeout waiting for navigation in tab %s", tab.ID)
+		case ev, ok := <-events:
+			if !ok {
+				return fmt.Errorf("tab events stream closed")
+			}
+			if ev.Tab.ID == tab.ID && ev.Type == TabClosed {
+				return fmt.Errorf("tab %s closed before navigation", tab.ID)
+			}
+		}
+	}
+}
+
+// blankURL возвращает true для URL пустой вкладки
+func blankURL(url string) bool {
+	return url == "" || url == "about:blank"
+}
+
+// ClickOnNewTabAndWait выполняет ClickOnNewTab и возвращает открытую им вкладку
+func (p *Page) ClickOnNewTabAndWait(selector string, timeout time.Duration) (*Page, Tab, error) {
+	return p.WaitForNewTab(func() error {
+		return p.ClickOnNewTab(selector)
+	}, timeout)
+}
+
+// ClickElementWithCtrlAndWait выполняет ClickElementWithCtrl и возвращает открытую им вкладку
+func (p *Page) ClickElementWithCtrlAndWait(selector string, timeout time.Duration) (*Page, Tab, error) {
+	return p.WaitForNewTab(func() error {
+		return p.ClickElementWithCtrl(selector)
+	}, timeout)
+}
+
+// waitForTab ждет в потоке событий создания вкладки, удовлетворяющей условию match
+func waitForTab(ctx context.Context, events <-chan TabEvent, match func(Tab) bool) (Tab, error) {
+	for {
+		select {
+		case <-ctx.Done():
+			return Tab{}, fmt.Errorf("timeout waiting for new tab")
+		case ev, ok := <-events:
+			if !ok {
+				return Tab{}, fmt.Errorf("tab events stream closed")
+			}
+			if ev.Type != TabCreated {
+				continue
+			}
+			if match == nil || match(ev.Tab) {
+				return ev.Tab, nil
+			}
+		}
+	}
+}
+
+// tabFromInfo преобразует информацию о target в Tab
+func tabFromInfo(t *target.Info) Tab {
+	return Tab{
+		ID:               t.TargetID,
+		Type:             t.Type,
+		Title:            t.Title,
+		URL:              t.URL,
+		Attached:         t.Attached,
+		OpenerID:         t.OpenerID,
+		BrowserContextID: t.BrowserContextID,
+	}
+}
+
+// tabEventQueue неограниченная очередь событий между обработчиком chromedp и подписчиком
+type tabEventQueue struct {
+	mu      sync.Mutex
+	pending []TabEvent
+	notify  chan struct{}
+}
+
+func newTabEventQueue() *tabEventQueue {
+	return &tabEventQueue{notify: make(chan struct{}, 1)}
+}
+
+// push добавляет событие в очередь без блокировки
+func (q *tabEventQueue) push(ev TabEvent) {
+	q.mu.Lock()
+	q.pending = append(q.pending, ev)
+	q.mu.Unlock()
+
+	select {
+	case q.notify <- struct{}{}:
+	default:
+	}
+}
+
+// pump доставляет события подписчику до отмены ctx, после чего закрывает канал
+func (q *tabEventQueue) pump(ctx context.Context, out chan<- TabEvent) {
+	defer close(out)
+
+	for {
+		q.mu.Lock()
+		batch := q.pending
+		q.pending = nil
+		q.mu.Unlock()
+
+		for _, ev := range batch {
+			select {
+			case out <- ev:
+			case <-ctx.Done():
+				return
+			}
+		}
+
+		if len(batch) == 0 {
+			select {
+			case <-q.notify:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}
+}
@@ -22,7 +22,10 @@ func main() {
 		Screen: &fp.Screen{
 			Width:            1920,
 			Height:           1080,
+			AvailWidth:       1920,
+			AvailHeight:      1040,
 			ColorDepth:       24,
+			PixelDepth:       24,
 			DevicePixelRatio: 1.0,
 		},
 		Timezone: &fp.Timezone{
@@ -31,7 +34,7 @@ func main() {
 		},
 		WebGL: &fp.WebGL{
 			Vendor:   "Google Inc. (NVIDIA)",
-			Renderer: "ANGLE (NVIDIA GeForce RTX 3080)",
+			Renderer: "ANGLE (NVIDIA, NVIDIA GeForce RTX 3080 Direct3D11 vs_5_0 ps_5_0, D3D11)",
 		},
 		Canvas: &fp.Canvas{
 			Noise: 0.02,
@@ -40,7 +43,7 @@ func main() {
 			Disable: true,
 		},
 		HardwareConcurrency: 16,
-		DeviceMemory:        32,
+		DeviceMemory:        8, // Chrome сообщает не больше 8
 	}
 
 	// Настраиваем опции браузера
@@ -54,6 +57,14 @@ func main() {
 		UserDataDir: "./chrome-data",
 	}
 
+	// Проверяем fingerprint на противоречия до запуска браузера
+	for _, issue := range osciris.ValidateBrowserOptions(options) {
+		log.Println(issue)
+		if issue.Severity == osciris.IssueError {
+			log.Fatal("fingerprint is inconsistent")
+		}
+	}
+
 	// Создаем браузер
 	browser, err := osciris.NewBrowser(ctx, options)
 	if err != nil {
@@ -61,6 +72,15 @@ func main() {
 	}
 	defer browser.Close()
 
+	// Проверяем, что страница видит именно заданный fingerprint (без сети)
+	report, err := browser.VerifyFingerprint(ctx)
+	if err != nil {
+		log.Fatal(err)
+	}
+	for _, mismatch := range report.Mismatches {
+		log.Println("fingerprint mismatch:", mismatch)
+	}
+
 	// Создаем страницу
 	page := browser.NewPage()
 
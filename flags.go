@@ -0,0 +1,49 @@
+package osciris
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/chromedp/chromedp"
+)
+
+// parseFlag разбирает флаг вида "name", "--name" или "--name=value"
+// Флаг без значения передается как булевый (--name)
+func parseFlag(flag string) (string, interface{}) {
+	flag = strings.TrimLeft(strings.TrimSpace(flag), "-")
+	if name, value, ok := strings.Cut(flag, "="); ok {
+		return name, value
+	}
+	return flag, true
+}
+
+// flagOptions преобразует Flags, FlagValues и RemoveFlags в опции allocator
+// Удаление применяется последним, поэтому убирает и флаги по умолчанию, и флаги из BrowserOptions
+func flagOptions(options *BrowserOptions) []chromedp.ExecAllocatorOption {
+	var opts []chromedp.ExecAllocatorOption
+
+	for _, flag := range options.Flags {
+		name, value := parseFlag(flag)
+		if name == "" {
+			continue
+		}
+		opts = append(opts, chromedp.Flag(name, value))
+	}
+
+	// ExecAllocator принимает только string и bool, поэтому числа и другие значения передаются строкой
+	for name, value := range options.FlagValues {
+		name, _ = parseFlag(name)
+		if _, ok := value.(bool); !ok {
+			value = fmt.Sprint(value)
+		}
+		opts = append(opts, chromedp.Flag(name, value))
+	}
+
+	// chromedp не передает флаги со значением false
+	for _, flag := range options.RemoveFlags {
+		name, _ := parseFlag(flag)
+		opts = append(opts, chromedp.Flag(name, false))
+	}
+
+	return opts
+}
@@ -0,0 +1,652 @@
+package osciris
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/chromedp/cdproto/cdp"
+	"github.com/chromedp/cdproto/network"
+	"github.com/chromedp/cdproto/page"
+	"github.com/chromedp/chromedp"
+)
+
+// HAR документ в формате HTTP Archive 1.2
+type HAR struct {
+	Log *HARLog `json:"log"`
+}
+
+// HARLog корневой объект HAR
+type HARLog struct {
+	Version string      `json:"version"`
+	Creator *HARCreator `json:"creator"`
+	Pages   []*HARPage  `json:"pages"`
+	Entries []*HAREntry `json:"entries"`
+}
+
+// HARCreator приложение, создавшее HAR
+type HARCreator struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+// HARPage страница (навигация верхнего уровня)
+type HARPage struct {
+	StartedDateTime time.Time       `json:"startedDateTime"`
+	ID              string          `json:"id"`
+	Title           string          `json:"title"`
+	PageTimings     *HARPageTimings `json:"pageTimings"`
+}
+
+// HARPageTimings время событий страницы в миллисекундах от начала навигации (-1, если неизвестно)
+type HARPageTimings struct {
+	OnContentLoad float64 `json:"onContentLoad"`
+	OnLoad        float64 `json:"onLoad"`
+}
+
+// HAREntry запрос и ответ
+type HAREntry struct {
+	Pageref         string       `json:"pageref,omitempty"`
+	StartedDateTime time.Time    `json:"startedDateTime"`
+	Time            float64      `json:"time"`
+	Request         *HARRequest  `json:"request"`
+	Response        *HARResponse `json:"response"`
+	Cache           struct{}     `json:"cache"`
+	Timings         *HARTimings  `json:"timings"`
+	ServerIPAddress string       `json:"serverIPAddress,omitempty"`
+	Connection      string       `json:"connection,omitempty"`
+	// ResourceType тип ресурса по классификации Chrome (расширение HAR)
+	ResourceType network.ResourceType `json:"_resourceType,omitempty"`
+	// Error текст сетевой ошибки, если запрос не завершился (расширение HAR)
+	Error string `json:"_error,omitempty"`
+}
+
+// HARRequest запрос
+type HARRequest struct {
+	Method      string          `json:"method"`
+	URL         string          `json:"url"`
+	HTTPVersion string          `json:"httpVersion"`
+	Cookies     []*HARCookie    `json:"cookies"`
+	Headers     []*HARNameValue `json:"headers"`
+	QueryString []*HARNameValue `json:"queryString"`
+	PostData    *HARPostData    `json:"postData,omitempty"`
+	HeadersSize int64           `json:"headersSize"`
+	BodySize    int64           `json:"bodySize"`
+}
+
+// HARResponse ответ
+type HARResponse struct {
+	Status      int64           `json:"status"`
+	StatusText  string          `json:"statusText"`
+	HTTPVersion string          `json:"httpVersion"`
+	Cookies     []*HARCookie    `json:"cookies"`
+	Headers     []*HARNameValue `json:"headers"`
+	Content     *HARContent     `json:"content"`
+	RedirectURL string          `json:"redirectURL"`
+	HeadersSize int64           `json:"headersSize"`
+	BodySize    int64           `json:"bodySize"`
+}
+
+// HARNameValue пара имя/значение (заголовки, параметры запроса)
+type HARNameValue struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// HARCookie cookie запроса или ответа
+type HARCookie struct {
+	Name     string `json:"name"`
+	Value    string `json:"value"`
+	Path     string `json:"path,omitempty"`
+	Domain   string `json:"domain,omitempty"`
+	Expires  string `json:"expires,omitempty"`
+	HTTPOnly bool   `json:"httpOnly,omitempty"`
+	Secure   bool   `json:"secure,omitempty"`
+}
+
+// HARPostData тело запроса
+type HARPostData struct {
+	MimeType string `json:"mimeType"`
+	Text     string `json:"text"`
+}
+
+// HARContent содержимое ответа
+type HARContent struct {
+	Size     int64  `json:"size"`
+	MimeType string `json:"mimeType"`
+	Text     string `json:"text,omitempty"`
+	Encoding string `json:"encoding,omitempty"`
+}
+
+// HARTimings фазы запроса в миллисекундах (-1, если фаза не применима)
+type HARTimings struct {
+	Blocked float64 `json:"blocked"`
+	DNS     float64 `json:"dns"`
+	Connect float64 `json:"connect"`
+	Send    float64 `json:"send"`
+	Wait    float64 `json:"wait"`
+	Receive float64 `json:"receive"`
+	SSL     float64 `json:"ssl"`
+}
+
+// HAROptions опции записи HAR
+type HAROptions struct {
+	// Bodies сохранять тела ответов (Network.getResponseBody)
+	Bodies bool
+
+	// MaxBodySize максимальный размер сохраняемого тела в байтах (0 - без ограничения)
+	MaxBodySize int
+}
+
+// WriteHAR записывает HAR в формате JSON
+func (h *HAR) WriteHAR(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(h)
+}
+
+// StartHAR начинает запись всех запросов и ответов страницы
+// Если options равен nil, тела ответов не сохраняются
+func (p *Page) StartHAR(options *HAROptions) error {
+	if options == nil {
+		options = &HAROptions{}
+	}
+
+	p.mu.Lock()
+	if p.har != nil {
+		p.mu.Unlock()
+		return fmt.Errorf("HAR recording already started")
+	}
+	rec := &harRecorder{
+		page:    p,
+		options: options,
+		entries: make(map[network.RequestID]*harEntryState),
+	}
+	p.har = rec
+	p.mu.Unlock()
+
+	// Корневой фрейм нужен, чтобы отличать навигации верхнего уровня от навигаций во фреймах
+	// Подписка выполняется до Network.enable, чтобы не пропустить первые запросы
+	var lctx context.Context
+	lctx, rec.cancel = context.WithCancel(p.ctx)
+	err := p.run(
+		chromedp.ActionFunc(func(ctx context.Context) error {
+			tree, err := page.GetFrameTree().Do(ctx)
+			if err != nil {
+				return err
+			}
+			rec.mu.Lock()
+			rec.mainFrame = tree.Frame.ID
+			rec.mu.Unlock()
+			chromedp.ListenTarget(lctx, rec.onEvent)
+			return nil
+		}),
+		network.Enable(),
+	)
+	if err != nil {
+		rec.cancel()
+		p.mu.Lock()
+		p.har = nil
+		p.mu.Unlock()
+		return fmt.Errorf("failed to start HAR recording: %w", err)
+	}
+
+	return nil
+}
+
+// StopHAR останавливает запись и возвращает собранный HAR
+func (p *Page) StopHAR() (*HAR, error) {
+	p.mu.Lock()
+	rec := p.har
+	p.har = nil
+	p.mu.Unlock()
+
+	if rec == nil {
+		return nil, fmt.Errorf("HAR recording not started")
+	}
+
+	// После остановки обработчик не запускает новые загрузки тел, поэтому Wait не гоняется с Add
+	rec.mu.Lock()
+	rec.stopped = true
+	rec.mu.Unlock()
+	rec.cancel()
+	// Дожидаемся загрузки тел ответов
+	rec.bodies.Wait()
+
+	return rec.build(), nil
+}
+
+// harEntryState состояние записи запроса до его завершения
+type harEntryState struct {
+	entry    *HAREntry
+	timing   *network.ResourceTiming
+	start    float64
+	bodySize int64
+	dataSize int64
+	sequence int
+}
+
+// harRecorder собирает события Network domain в HAR
+type harRecorder struct {
+	page    *Page
+	options *HAROptions
+	cancel  context.CancelFunc
+	bodies  sync.WaitGroup
+
+	mu        sync.Mutex
+	stopped   bool
+	mainFrame cdp.FrameID
+	pages     []*HARPage
+	pageStart float64
+	entries   map[network.RequestID]*harEntryState
+	done      []*harEntryState
+	sequence  int
+}
+
+// onEvent обрабатывает события вкладки; вызывается синхронно из цикла событий chromedp
+func (r *harRecorder) onEvent(ev any) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.stopped {
+		return
+	}
+
+	switch ev := ev.(type) {
+	case *network.EventRequestWillBeSent:
+		// Редирект завершает предыдущий запрос с тем же ID
+		if ev.RedirectResponse != nil {
+			if st, ok := r.entries[ev.RequestID]; ok {
+				r.applyResponse(st, ev.RedirectResponse)
+				st.entry.Response.RedirectURL = ev.Request.URL
+				r.finish(st, monotonicSeconds(ev.Timestamp))
+				delete(r.entries, ev.RequestID)
+			}
+		}
+
+		start := monotonicSeconds(ev.Timestamp)
+		if ev.Type == network.ResourceTypeDocument && ev.FrameID == r.mainFrame && string(ev.RequestID) == string(ev.LoaderID) {
+			r.pages = append(r.pages, &HARPage{
+				StartedDateTime: wallTime(ev.WallTime),
+				ID:              fmt.Sprintf("page_%d", len(r.pages)+1),
+				Title:           ev.Request.URL,
+				PageTimings:     &HARPageTimings{OnContentLoad: -1, OnLoad: -1},
+			})
+			r.pageStart = start
+		}
+
+		r.sequence++
+		st := &harEntryState{
+			start:    start,
+			sequence: r.sequence,
+			entry: &HAREntry{
+				StartedDateTime: wallTime(ev.WallTime),
+				Request:         harRequest(ev.Request),
+				Response: &HARResponse{
+					Cookies:     []*HARCookie{},
+					Headers:     []*HARNameValue{},
+					Content:     &HARContent{},
+					HeadersSize: -1,
+					BodySize:    -1,
+				},
+				Timings:      &HARTimings{Blocked: -1, DNS: -1, Connect: -1, SSL: -1},
+				ResourceType: ev.Type,
+			},
+		}
+		if len(r.pages) > 0 {
+			st.entry.Pageref = r.pages[len(r.pages)-1].ID
+		}
+		r.entries[ev.RequestID] = st
+
+	case *network.EventResponseReceived:
+		if st, ok := r.entries[ev.RequestID]; ok {
+			r.applyResponse(st, ev.Response)
+		}
+
+	// Заголовки Cookie и Set-Cookie сетевого стека приходят отдельными событиями
+	case *network.EventRequestWillBeSentExtraInfo:
+		if st, ok := r.entries[ev.RequestID]; ok {
+			st.entry.Request.Headers = harHeaders(ev.Headers)
+			if cookies := requestCookies(ev.Headers); len(cookies) > 0 {
+				st.entry.Request.Cookies = cookies
+			}
+		}
+
+	case *network.EventResponseReceivedExtraInfo:
+		if st, ok := r.entries[ev.RequestID]; ok {
+			if cookies := responseCookies(ev.Headers); len(cookies) > 0 {
+				st.entry.Response.Cookies = cookies
+			}
+		}
+
+	case *network.EventDataReceived:
+		if st, ok := r.entries[ev.RequestID]; ok {
+			st.dataSize += ev.DataLength
+			st.bodySize += ev.EncodedDataLength
+		}
+
+	case *network.EventLoadingFinished:
+		st, ok := r.entries[ev.RequestID]
+		if !ok {
+			return
+		}
+		delete(r.entries, ev.RequestID)
+		r.finish(st, monotonicSeconds(ev.Timestamp))
+
+		if r.options.Bodies {
+			r.bodies.Add(1)
+			go r.fetchBody(ev.RequestID, st)
+		}
+
+	case *network.EventLoadingFailed:
+		st, ok := r.entries[ev.RequestID]
+		if !ok {
+			return
+		}
+		delete(r.entries, ev.RequestID)
+		st.entry.Error = ev.ErrorText
+		r.finish(st, monotonicSeconds(ev.Timestamp))
+
+	case *page.EventDomContentEventFired:
+		if len(r.pages) > 0 {
+			r.pages[len(r.pages)-1].PageTimings.OnContentLoad = (monotonicSeconds(ev.Timestamp) - r.pageStart) * 1000
+		}
+
+	case *page.EventLoadEventFired:
+		if len(r.pages) > 0 {
+			r.pages[len(r.pages)-1].PageTimings.OnLoad = (monotonicSeconds(ev.Timestamp) - r.pageStart) * 1000
+		}
+	}
+}
+
+// applyResponse переносит данные ответа в запись
+func (r *harRecorder) applyResponse(st *harEntryState, resp *network.Response) {
+	entry := st.entry
+	entry.Response.Status = resp.Status
+	entry.Response.StatusText = resp.StatusText
+	entry.Response.HTTPVersion = httpVersion(resp.Protocol)
+	entry.Response.Headers = harHeaders(resp.Headers)
+	entry.Response.Content.MimeType = resp.MimeType
+	entry.Request.HTTPVersion = entry.Response.HTTPVersion
+	if resp.RequestHeaders != nil {
+		entry.Request.Headers = harHeaders(resp.RequestHeaders)
+		if cookies := requestCookies(resp.RequestHeaders); len(cookies) > 0 {
+			entry.Request.Cookies = cookies
+		}
+	}
+	if cookies := responseCookies(resp.Headers); len(cookies) > 0 {
+		entry.Response.Cookies = cookies
+	}
+	entry.ServerIPAddress = resp.RemoteIPAddress
+	if resp.ConnectionID != 0 {
+		entry.Connection = fmt.Sprintf("%.0f", resp.ConnectionID)
+	}
+	st.timing = resp.Timing
+}
+
+// finish рассчитывает тайминги и переносит запись в список завершенных
+func (r *harRecorder) finish(st *harEntryState, end float64) {
+	entry := st.entry
+	entry.Response.Content.Size = st.dataSize
+	entry.Response.BodySize = st.bodySize
+
+	t := entry.Timings
+	if timing := st.timing; timing != nil {
+		t.Blocked = firstNonNegative(timing.DNSStart, timing.ConnectStart, timing.SendStart)
+		t.DNS = phase(timing.DNSStart, timing.DNSEnd)
+		t.Connect = phase(timing.ConnectStart, timing.ConnectEnd)
+		t.SSL = phase(timing.SslStart, timing.SslEnd)
+		t.Send = phase(timing.SendStart, timing.SendEnd)
+		if t.Send < 0 {
+			t.Send = 0
+		}
+		t.Wait = timing.ReceiveHeadersEnd - timing.SendEnd
+		t.Receive = (end-timing.RequestTime)*1000 - timing.ReceiveHeadersEnd
+		if t.Wait < 0 {
+			t.Wait = 0
+		}
+		if t.Receive < 0 {
+			t.Receive = 0
+		}
+	} else {
+		t.Send = 0
+		t.Wait = (end - st.start) * 1000
+		t.Receive = 0
+		if t.Wait < 0 {
+			t.Wait = 0
+		}
+	}
+
+	// ssl входит в connect, поэтому не учитывается в общем времени
+	entry.Time = 0
+	for _, v := range []float64{t.Blocked, t.DNS, t.Connect, t.Send, t.Wait, t.Receive} {
+		if v > 0 {
+			entry.Time += v
+		}
+	}
+
+	r.done = append(r.done, st)
+}
+
+// fetchBody загружает тело ответа после завершения запроса
+func (r *harRecorder) fetchBody(id network.RequestID, st *harEntryState) {
+	defer r.bodies.Done()
+
+	var body []byte
+	err := r.page.run(chromedp.ActionFunc(func(ctx context.Context) error {
+		var err error
+		body, err = network.GetResponseBody(id).Do(ctx)
+		return err
+	}))
+	if err != nil {
+		// Тело может быть недоступно (редирект, ответ без тела, выгруженный ресурс)
+		return
+	}
+	if r.options.MaxBodySize > 0 && len(body) > r.options.MaxBodySize {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	content := st.entry.Response.Content
+	if utf8.Valid(body) {
+		content.Text = string(body)
+	} else {
+		content.Text = base64.StdEncoding.EncodeToString(body)
+		content.Encoding = "base64"
+	}
+	if content.Size == 0 {
+		content.Size = int64(len(body))
+	}
+}
+
+// build собирает HAR из завершенных и незавершенных запросов
+func (r *harRecorder) build() *HAR {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	states := append([]*harEntryState{}, r.done...)
+	for _, st := range r.entries {
+		states = append(states, st)
+	}
+	sort.Slice(states, func(i, j int) bool {
+		return states[i].sequence < states[j].sequence
+	})
+
+	entries := make([]*HAREntry, 0, len(states))
+	for _, st := range states {
+		entries = append(entries, st.entry)
+	}
+
+	pages := r.pages
+	if pages == nil {
+		pages = []*HARPage{}
+	}
+
+	return &HAR{
+		Log: &HARLog{
+			Version: "1.2",
+			Creator: &HARCreator{Name: "osciris", Version: "1.0"},
+			Pages:   pages,
+			Entries: entries,
+		},
+	}
+}
+
+// harRequest преобразует запрос Network domain в HARRequest
+func harRequest(req *network.Request) *HARRequest {
+	hr := &HARRequest{
+		Method:      req.Method,
+		URL:         req.URL + req.URLFragment,
+		HTTPVersion: "HTTP/1.1",
+		Cookies:     requestCookies(req.Headers),
+		Headers:     harHeaders(req.Headers),
+		QueryString: []*HARNameValue{},
+		HeadersSize: -1,
+		BodySize:    0,
+	}
+
+	if u, err := url.Parse(req.URL); err == nil {
+		for name, values := range u.Query() {
+			for _, value := range values {
+				hr.QueryString = append(hr.QueryString, &HARNameValue{Name: name, Value: value})
+			}
+		}
+	}
+
+	if body := postData(req); len(body) > 0 {
+		mimeType := ""
+		for name, value := range req.Headers {
+			if strings.EqualFold(name, "Content-Type") {
+				mimeType = fmt.Sprint(value)
+			}
+		}
+		hr.PostData = &HARPostData{MimeType: mimeType, Text: string(body)}
+		hr.BodySize = int64(len(body))
+	}
+
+	return hr
+}
+
+// harHeaders преобразует заголовки в список HARNameValue
+func harHeaders(headers network.Headers) []*HARNameValue {
+	result := make([]*HARNameValue, 0, len(headers))
+	for name, value := range headers {
+		// Несколько значений одного заголовка Chrome передает через перевод строки
+		for _, v := range strings.Split(fmt.Sprint(value), "\n") {
+			result = append(result, &HARNameValue{Name: name, Value: v})
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Name < result[j].Name
+	})
+	return result
+}
+
+// requestCookies разбирает заголовок Cookie запроса
+func requestCookies(headers network.Headers) []*HARCookie {
+	cookies := []*HARCookie{}
+	for name, value := range headers {
+		if !strings.EqualFold(name, "Cookie") {
+			continue
+		}
+		parsed, err := http.ParseCookie(fmt.Sprint(value))
+		if err != nil {
+			continue
+		}
+		for _, c := range parsed {
+			cookies = append(cookies, &HARCookie{Name: c.Name, Value: c.Value})
+		}
+	}
+	return cookies
+}
+
+// responseCookies разбирает заголовки Set-Cookie ответа
+func responseCookies(headers network.Headers) []*HARCookie {
+	cookies := []*HARCookie{}
+	for name, value := range headers {
+		if !strings.EqualFold(name, "Set-Cookie") {
+			continue
+		}
+		for _, line := range strings.Split(fmt.Sprint(value), "\n") {
+			c, err := http.ParseSetCookie(line)
+			if err != nil {
+				continue
+			}
+			cookie := &HARCookie{
+				Name:     c.Name,
+				Value:    c.Value,
+				Path:     c.Path,
+				Domain:   c.Domain,
+				HTTPOnly: c.HttpOnly,
+				Secure:   c.Secure,
+			}
+			if !c.Expires.IsZero() {
+				cookie.Expires = c.Expires.UTC().Format(time.RFC3339)
+			}
+			cookies = append(cookies, cookie)
+		}
+	}
+	return cookies
+}
+
+// httpVersion преобразует протокол Chrome в версию HTTP для HAR
+func httpVersion(protocol string) string {
+	switch strings.ToLower(protocol) {
+	case "":
+		return "HTTP/1.1"
+	case "h2":
+		return "HTTP/2.0"
+	case "h3":
+		return "HTTP/3.0"
+	case "http/1.0":
+		return "HTTP/1.0"
+	case "http/1.1":
+		return "HTTP/1.1"
+	default:
+		return strings.ToUpper(protocol)
+	}
+}
+
+// phase возвращает длительность фазы или -1, если фаза не применима
+func phase(start, end float64) float64 {
+	if start < 0 || end < 0 {
+		return -1
+	}
+	return end - start
+}
+
+// firstNonNegative возвращает первое неотрицательное значение или -1
+func firstNonNegative(values ...float64) float64 {
+	for _, v := range values {
+		if v >= 0 {
+			return v
+		}
+	}
+	return -1
+}
+
+// monotonicSeconds переводит MonotonicTime в секунды шкалы ResourceTiming.RequestTime
+func monotonicSeconds(t *cdp.MonotonicTime) float64 {
+	if t == nil {
+		return 0
+	}
+	return t.Time().Sub(*cdp.MonotonicTimeEpoch).Seconds()
+}
+
+// wallTime переводит TimeSinceEpoch в time.Time
+func wallTime(t *cdp.TimeSinceEpoch) time.Time {
+	if t == nil {
+		return time.Now()
+	}
+	return t.Time()
+}
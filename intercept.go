@@ -0,0 +1,331 @@
+package osciris
+
+import (
+	"encoding/base64"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/chromedp/cdproto/fetch"
+	"github.com/chromedp/cdproto/network"
+	"github.com/chromedp/chromedp"
+)
+
+// RequestPattern шаблон перехватываемых запросов
+type RequestPattern struct {
+	// URL шаблон URL: '*' - любое количество символов, '?' - ровно один символ, '\' - экранирование
+	// Пустой шаблон равносилен "*"
+	URL string
+
+	// ResourceType тип ресурса (network.ResourceTypeImage, network.ResourceTypeScript и т.д.)
+	// Пустое значение - любой тип
+	ResourceType network.ResourceType
+}
+
+// InterceptHandler обработчик перехваченного запроса
+// Обработчик выбирает судьбу запроса вызовом Continue, ContinueWith, Fulfill или Fail;
+// если ни один из них не вызван, запрос продолжается без изменений
+type InterceptHandler func(req *InterceptedRequest)
+
+// InterceptedRequest запрос, приостановленный через Fetch domain
+type InterceptedRequest struct {
+	URL          string
+	Method       string
+	Headers      map[string]string
+	PostData     []byte
+	ResourceType network.ResourceType
+
+	id         fetch.RequestID
+	resolution chromedp.Action
+}
+
+// RequestOverrides изменения запроса при продолжении
+// Пустые поля оставляют исходные значения
+type RequestOverrides struct {
+	URL    string
+	Method string
+	// Headers полностью заменяет заголовки запроса; чтобы добавить заголовок, дополните копию req.Headers
+	Headers  map[string]string
+	PostData []byte
+}
+
+// Continue продолжает запрос без изменений
+func (r *InterceptedRequest) Continue() {
+	r.resolution = fetch.ContinueRequest(r.id)
+}
+
+// ContinueWith продолжает запрос с измененными URL, методом, заголовками или телом
+func (r *InterceptedRequest) ContinueWith(o RequestOverrides) {
+	params := fetch.ContinueRequest(r.id)
+	if o.URL != "" {
+		params = params.WithURL(o.URL)
+	}
+	if o.Method != "" {
+		params = params.WithMethod(o.Method)
+	}
+	if o.Headers != nil {
+		params = params.WithHeaders(headerEntries(o.Headers))
+	}
+	if o.PostData != nil {
+		params = params.WithPostData(base64.StdEncoding.EncodeToString(o.PostData))
+	}
+	r.resolution = params
+}
+
+// Fulfill отвечает на запрос синтетическим ответом, не отправляя его в сеть
+func (r *InterceptedRequest) Fulfill(status int64, headers map[string]string, body []byte) {
+	params := fetch.FulfillRequest(r.id, status)
+	if len(headers) > 0 {
+		params = params.WithResponseHeaders(headerEntries(headers))
+	}
+	if len(body) > 0 {
+		params = params.WithBody(base64.StdEncoding.EncodeToString(body))
+	}
+	r.resolution = params
+}
+
+// Fail завершает запрос с ошибкой (network.ErrorReasonBlockedByClient, network.ErrorReasonAborted и т.д.)
+func (r *InterceptedRequest) Fail(reason network.ErrorReason) {
+	r.resolution = fetch.FailRequest(r.id, reason)
+}
+
+// Intercept перехватывает запросы страницы, подходящие под patterns, и передает их в handler
+// Обработчики, зарегистрированные позже, имеют приоритет; запросы, не подошедшие ни под один шаблон, продолжаются
+// Запросы, заблокированные через Block или BrowserOptions.Block, до обработчиков не доходят
+// Возвращает функцию, отменяющую перехват
+func (p *Page) Intercept(patterns []RequestPattern, handler InterceptHandler) (func() error, error) {
+	if len(patterns) == 0 {
+		patterns = []RequestPattern{{URL: "*"}}
+	}
+
+	r := &route{handler: handler}
+	for _, pattern := range patterns {
+		re, err := compileURLPattern(pattern.URL)
+		if err != nil {
+			return nil, err
+		}
+		r.patterns = append(r.patterns, pattern)
+		r.urls = append(r.urls, re)
+	}
+
+	router := p.fetchRouter()
+	if err := router.addRoute(r); err != nil {
+		return nil, fmt.Errorf("failed to enable interception: %w", err)
+	}
+
+	return func() error {
+		return router.removeRoute(r)
+	}, nil
+}
+
+// route зарегистрированный обработчик перехвата
+// block политика блокировки (nil - обычный обработчик Intercept)
+type route struct {
+	patterns []RequestPattern
+	urls     []*regexp.Regexp
+	handler  InterceptHandler
+	block    *blocker
+}
+
+// match проверяет, подходит ли запрос под один из шаблонов
+func (r *route) match(url string, resourceType network.ResourceType) bool {
+	for i, pattern := range r.patterns {
+		if pattern.ResourceType != "" && pattern.ResourceType != resourceType {
+			continue
+		}
+		if r.urls[i].MatchString(url) {
+			return true
+		}
+	}
+	return false
+}
+
+// fetchRouter управляет Fetch domain вкладки и распределяет приостановленные запросы по обработчикам
+// Fetch.enable заменяет шаблоны предыдущего вызова, поэтому все возможности, использующие Fetch, проходят через router
+type fetchRouter struct {
+	page *Page
+
+	mu     sync.Mutex
+	routes []*route
+	// auth учетные данные для ответа на authRequired от прокси
+	auth *fetch.AuthChallengeResponse
+}
+
+// fetchRouter возвращает router страницы, создавая его при первом обращении
+func (p *Page) fetchRouter() *fetchRouter {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.router == nil {
+		p.router = &fetchRouter{page: p}
+		chromedp.ListenTarget(p.ctx, p.router.onEvent)
+	}
+	return p.router
+}
+
+// addRoute регистрирует обработчик и обновляет шаблоны Fetch domain
+func (fr *fetchRouter) addRoute(r *route) error {
+	fr.mu.Lock()
+	fr.routes = append(fr.routes, r)
+	fr.mu.Unlock()
+
+	return fr.sync()
+}
+
+// removeRoute удаляет обработчик и обновляет шаблоны Fetch domain
+func (fr *fetchRouter) removeRoute(r *route) error {
+	fr.mu.Lock()
+	for i, existing := range fr.routes {
+		if existing == r {
+			fr.routes = append(fr.routes[:i], fr.routes[i+1:]...)
+			break
+		}
+	}
+	fr.mu.Unlock()
+
+	return fr.sync()
+}
+
+// sync включает Fetch domain с объединением шаблонов всех обработчиков или выключает его
+func (fr *fetchRouter) sync() error {
+	fr.mu.Lock()
+	handleAuth := fr.auth != nil
+	var patterns []*fetch.RequestPattern
+	for _, r := range fr.routes {
+		for _, pattern := range r.patterns {
+			patterns = append(patterns, &fetch.RequestPattern{
+				URLPattern:   pattern.URL,
+				ResourceType: pattern.ResourceType,
+				RequestStage: fetch.RequestStageRequest,
+			})
+		}
+	}
+	fr.mu.Unlock()
+
+	// authRequired приходит только для приостановленных запросов, поэтому при авторизации перехватываются все
+	if handleAuth {
+		patterns = []*fetch.RequestPattern{{URLPattern: "*", RequestStage: fetch.RequestStageRequest}}
+	}
+
+	if len(patterns) == 0 {
+		return fr.page.run(fetch.Disable())
+	}
+	return fr.page.run(fetch.Enable().WithPatterns(patterns).WithHandleAuthRequests(handleAuth))
+}
+
+// onEvent получает события вкладки; обработка выполняется в отдельной горутине, чтобы не блокировать chromedp
+func (fr *fetchRouter) onEvent(ev any) {
+	switch ev := ev.(type) {
+	case *fetch.EventRequestPaused:
+		go fr.handleRequest(ev)
+	case *fetch.EventAuthRequired:
+		go fr.handleAuth(ev)
+	}
+}
+
+// handleRequest блокирует запрос по политикам блокировки или передает его подходящему обработчику
+// и применяет его решение
+func (fr *fetchRouter) handleRequest(ev *fetch.EventRequestPaused) {
+	req := &InterceptedRequest{
+		URL:          ev.Request.URL,
+		Method:       ev.Request.Method,
+		Headers:      headersMap(ev.Request.Headers),
+		PostData:     postData(ev.Request),
+		ResourceType: ev.ResourceType,
+		id:           ev.RequestID,
+	}
+
+	fr.mu.Lock()
+	blocked := false
+	for _, r := range fr.routes {
+		if r.block != nil && r.match(req.URL, req.ResourceType) && r.block.match(req) {
+			blocked = true
+			break
+		}
+	}
+	var handler InterceptHandler
+	for i := len(fr.routes) - 1; i >= 0 && !blocked; i-- {
+		if fr.routes[i].block == nil && fr.routes[i].match(req.URL, req.ResourceType) {
+			handler = fr.routes[i].handler
+			break
+		}
+	}
+	fr.mu.Unlock()
+
+	if blocked {
+		req.Fail(network.ErrorReasonBlockedByClient)
+	} else if handler != nil {
+		handler(req)
+	}
+	if req.resolution == nil {
+		req.Continue()
+	}
+
+	// Ошибку игнорируем: запрос мог быть отменен страницей до ответа обработчика
+	fr.page.run(req.resolution)
+}
+
+// compileURLPattern преобразует шаблон URL Fetch domain в регулярное выражение
+func compileURLPattern(pattern string) (*regexp.Regexp, error) {
+	if pattern == "" {
+		pattern = "*"
+	}
+
+	var sb strings.Builder
+	sb.WriteString("^")
+	escaped := false
+	for _, r := range pattern {
+		switch {
+		case escaped:
+			sb.WriteString(regexp.QuoteMeta(string(r)))
+			escaped = false
+		case r == '\\':
+			escaped = true
+		case r == '*':
+			sb.WriteString(".*")
+		case r == '?':
+			sb.WriteString(".")
+		default:
+			sb.WriteString(regexp.QuoteMeta(string(r)))
+		}
+	}
+	sb.WriteString("$")
+
+	re, err := regexp.Compile(sb.String())
+	if err != nil {
+		return nil, fmt.Errorf("invalid URL pattern %q: %w", pattern, err)
+	}
+	return re, nil
+}
+
+// headerEntries преобразует заголовки в формат Fetch domain
+func headerEntries(headers map[string]string) []*fetch.HeaderEntry {
+	entries := make([]*fetch.HeaderEntry, 0, len(headers))
+	for name, value := range headers {
+		entries = append(entries, &fetch.HeaderEntry{Name: name, Value: value})
+	}
+	return entries
+}
+
+// headersMap преобразует заголовки Network domain в map[string]string
+func headersMap(headers network.Headers) map[string]string {
+	result := make(map[string]string, len(headers))
+	for name, value := range headers {
+		result[name] = fmt.Sprint(value)
+	}
+	return result
+}
+
+// postData собирает тело запроса из postDataEntries
+func postData(req *network.Request) []byte {
+	var data []byte
+	for _, entry := range req.PostDataEntries {
+		decoded, err := base64.StdEncoding.DecodeString(entry.Bytes)
+		if err != nil {
+			continue
+		}
+		data = append(data, decoded...)
+	}
+	return data
+}
@@ -0,0 +1,306 @@
+package osciris
+
+import (
+	"context"
+	"math"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/chromedp/cdproto/dom"
+	"github.com/chromedp/cdproto/input"
+	"github.com/chromedp/chromedp"
+)
+
+// HumanMouseOptions настройки человекоподобного движения мыши
+type HumanMouseOptions struct {
+	// Seed инициализирует генератор случайных чисел; одинаковый seed дает одинаковые траектории и задержки (0 - случайный)
+	Seed int64
+	// Speed множитель скорости движения (0 - 1.0)
+	Speed float64
+	// Overshoot вероятность промаха для движений длиннее 200px (0 - 0.3, отрицательное значение отключает промахи)
+	Overshoot float64
+	// Tremor амплитуда дрожания руки в пикселях (0 - 0.5, отрицательное значение отключает дрожание)
+	Tremor float64
+}
+
+// humanMouse генератор траекторий курсора (WindMouse: траектория под действием «гравитации» к цели и случайного «ветра»)
+type humanMouse struct {
+	mu        sync.Mutex
+	rng       *rand.Rand
+	speed     float64
+	overshoot float64
+	tremor    float64
+}
+
+// tabSeeds число вкладок, получивших настройки с Seed (смещение Seed следующей вкладки)
+// Счетчик общий для Browser одного allocator, чтобы вкладки OpenTab и ConnectToTab не повторяли поток случайных чисел
+type tabSeeds struct {
+	mu     sync.Mutex
+	mouse  int64
+	typing int64
+}
+
+// mouseStep точка траектории и задержка перед переходом в нее
+type mouseStep struct {
+	Point
+	delay time.Duration
+}
+
+// newHumanMouse создает генератор траекторий (nil - настройки по умолчанию)
+func newHumanMouse(options *HumanMouseOptions) *humanMouse {
+	if options == nil {
+		options = &HumanMouseOptions{}
+	}
+	seed := options.Seed
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+
+	m := &humanMouse{
+		rng:       rand.New(rand.NewSource(seed)),
+		speed:     options.Speed,
+		overshoot: options.Overshoot,
+		tremor:    options.Tremor,
+	}
+	if m.speed <= 0 {
+		m.speed = 1
+	}
+	if m.overshoot == 0 {
+		m.overshoot = 0.3
+	}
+	if m.tremor == 0 {
+		m.tremor = 0.5
+	}
+	return m
+}
+
+// EnableHumanMouse включает человекоподобное движение мыши для click-хелперов страницы
+// Клик по селектору попадает в случайную точку элемента, клик по координатам - точно в них
+func (p *Page) EnableHumanMouse(options *HumanMouseOptions) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.mouse = newHumanMouse(options)
+}
+
+// DisableHumanMouse выключает человекоподобное движение мыши
+func (p *Page) DisableHumanMouse() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.mouse = nil
+}
+
+// CursorPosition возвращает последнюю позицию курсора; false, если мышь еще не перемещалась
+func (p *Page) CursorPosition() (Point, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.cursor, p.cursorKnown
+}
+
+// humanMouseOptions возвращает настройки мыши новой вкладки: при заданном Seed у каждой вкладки свой поток случайных чисел
+func (b *Browser) humanMouseOptions() *HumanMouseOptions {
+	options := *b.options.HumanMouse
+	if options.Seed != 0 {
+		b.seeds.mu.Lock()
+		options.Seed += b.seeds.mouse
+		b.seeds.mouse++
+		b.seeds.mu.Unlock()
+	}
+	return &options
+}
+
+// humanMouse возвращает генератор траекторий, если режим включен
+func (p *Page) humanMouse() *humanMouse {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.mouse
+}
+
+// setCursor запоминает позицию курсора
+func (p *Page) setCursor(x, y float64) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.cursor = Point{X: x, Y: y}
+	p.cursorKnown = true
+}
+
+// humanMove ведет курсор от последней позиции к to
+func (p *Page) humanMove(ctx context.Context, m *humanMouse, to Point) error {
+	from, known := p.CursorPosition()
+	if !known {
+		from = m.startPoint(to)
+	}
+
+	for _, step := range m.path(from, to) {
+		if err := sleep(ctx, step.delay); err != nil {
+			return err
+		}
+		if err := input.DispatchMouseEvent(input.MouseMoved, step.X, step.Y).Do(ctx); err != nil {
+			return err
+		}
+		p.setCursor(step.X, step.Y)
+	}
+	return nil
+}
+
+// humanClick ведет курсор к точке и кликает с паузой перед нажатием и случайной длительностью нажатия
+func (p *Page) humanClick(ctx context.Context, m *humanMouse, to Point, button input.MouseButton, modifiers input.Modifier) error {
+	if err := p.humanMove(ctx, m, to); err != nil {
+		return err
+	}
+	if err := sleep(ctx, m.duration(60*time.Millisecond, 180*time.Millisecond)); err != nil {
+		return err
+	}
+
+	if err := input.DispatchMouseEvent(input.MousePressed, to.X, to.Y).
+		WithButton(button).
+		WithModifiers(modifiers).
+		WithClickCount(1).
+		Do(ctx); err != nil {
+		return err
+	}
+	// Кнопка отпускается и после отмены ctx, иначе она останется зажатой
+	sleepErr := sleep(ctx, m.duration(50*time.Millisecond, 130*time.Millisecond))
+	releaseCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), releaseTimeout)
+	defer cancel()
+	err := input.DispatchMouseEvent(input.MouseReleased, to.X, to.Y).
+		WithButton(button).
+		WithModifiers(modifiers).
+		WithClickCount(1).
+		Do(releaseCtx)
+	if sleepErr != nil {
+		return sleepErr
+	}
+	return err
+}
+
+// humanClickSelector прокручивает к элементу и кликает в случайную точку ближе к его центру
+func (p *Page) humanClickSelector(m *humanMouse, selector string, modifiers input.Modifier) chromedp.ActionFunc {
+	return func(ctx context.Context) error {
+		q, err := nodeQuad(ctx, selector)
+		if err != nil {
+			return err
+		}
+		return p.humanClick(ctx, m, m.pointIn(q), input.Left, modifiers)
+	}
+}
+
+// path строит траекторию от from до to; длинные движения иногда промахиваются и корректируются
+func (m *humanMouse) path(from, to Point) []mouseStep {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	distance := math.Hypot(to.X-from.X, to.Y-from.Y)
+	if distance > 200 && m.rng.Float64() < m.overshoot {
+		// Точка промаха за целью по направлению движения со смещением вбок
+		over := distance * (0.03 + m.rng.Float64()*0.05)
+		dx, dy := (to.X-from.X)/distance, (to.Y-from.Y)/distance
+		side := (m.rng.Float64()*2 - 1) * over / 2
+		miss := Point{X: to.X + dx*over - dy*side, Y: to.Y + dy*over + dx*side}
+
+		steps := m.steps(from, miss, m.maxStep(distance))
+		correction := m.steps(miss, to, 3+m.rng.Float64()*2)
+		// Пауза, пока человек замечает промах
+		correction[0].delay += 80*time.Millisecond + time.Duration(m.rng.Int63n(int64(120*time.Millisecond)))
+		return append(steps, correction...)
+	}
+	return m.steps(from, to, m.maxStep(distance))
+}
+
+// maxStep максимальная длина шага: длинные движения быстрее
+func (m *humanMouse) maxStep(distance float64) float64 {
+	return math.Min(math.Max(distance/15, 6), 30) * m.speed
+}
+
+// steps строит траекторию WindMouse и назначает задержки между событиями
+func (m *humanMouse) steps(from, to Point, maxStep float64) []mouseStep {
+	const (
+		gravity    = 9.0
+		wind       = 3.0
+		targetArea = 10.0
+		maxPoints  = 2000
+	)
+	sqrt3, sqrt5 := math.Sqrt(3), math.Sqrt(5)
+
+	var points []Point
+	x, y := from.X, from.Y
+	var vx, vy, wx, wy float64
+	for dist := math.Hypot(to.X-x, to.Y-y); dist >= 1 && len(points) < maxPoints; dist = math.Hypot(to.X-x, to.Y-y) {
+		w := math.Min(wind, dist)
+		if dist >= targetArea {
+			wx = wx/sqrt3 + (m.rng.Float64()*2-1)*w/sqrt5
+			wy = wy/sqrt3 + (m.rng.Float64()*2-1)*w/sqrt5
+		} else {
+			// У цели «ветер» стихает, а шаги укорачиваются: курсор замедляется
+			wx /= sqrt3
+			wy /= sqrt3
+			if maxStep < 3 {
+				maxStep = m.rng.Float64()*3 + 3
+			} else {
+				maxStep /= sqrt5
+			}
+		}
+
+		vx += wx + gravity*(to.X-x)/dist
+		vy += wy + gravity*(to.Y-y)/dist
+		if v := math.Hypot(vx, vy); v > maxStep {
+			clip := maxStep/2 + m.rng.Float64()*maxStep/2
+			vx, vy = vx/v*clip, vy/v*clip
+		}
+		x += vx
+		y += vy
+
+		point := Point{X: x, Y: y}
+		if m.tremor > 0 {
+			point.X += m.rng.NormFloat64() * m.tremor
+			point.Y += m.rng.NormFloat64() * m.tremor
+		}
+		points = append(points, point)
+	}
+	points = append(points, to)
+
+	steps := make([]mouseStep, len(points))
+	for i, point := range points {
+		// 6-14ms между событиями: частота событий настоящей мыши 60-125 Гц
+		delay := (6 + m.rng.Float64()*8) / m.speed
+		steps[i] = mouseStep{Point: point, delay: time.Duration(delay * float64(time.Millisecond))}
+	}
+	return steps
+}
+
+// startPoint выбирает позицию, откуда курсор «входит» к цели, если его позиция еще неизвестна
+func (m *humanMouse) startPoint(to Point) Point {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	angle := m.rng.Float64() * 2 * math.Pi
+	distance := 150 + m.rng.Float64()*250
+	return Point{
+		X: math.Max(0, to.X+math.Cos(angle)*distance),
+		Y: math.Max(0, to.Y+math.Sin(angle)*distance),
+	}
+}
+
+// pointIn выбирает точку внутри элемента с нормальным распределением вокруг центра
+func (m *humanMouse) pointIn(q dom.Quad) Point {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	minX, maxX := math.Min(math.Min(q[0], q[2]), math.Min(q[4], q[6])), math.Max(math.Max(q[0], q[2]), math.Max(q[4], q[6]))
+	minY, maxY := math.Min(math.Min(q[1], q[3]), math.Min(q[5], q[7])), math.Max(math.Max(q[1], q[3]), math.Max(q[5], q[7]))
+	clamp := func(v, lo, hi float64) float64 { return math.Max(lo, math.Min(hi, v)) }
+
+	centerX, centerY := (minX+maxX)/2, (minY+maxY)/2
+	width, height := maxX-minX, maxY-minY
+	return Point{
+		X: clamp(centerX+m.rng.NormFloat64()*width/6, minX+width*0.1, maxX-width*0.1),
+		Y: clamp(centerY+m.rng.NormFloat64()*height/6, minY+height*0.1, maxY-height*0.1),
+	}
+}
+
+// duration возвращает случайную длительность в диапазоне [min, max)
+func (m *humanMouse) duration(min, max time.Duration) time.Duration {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return min + time.Duration(m.rng.Int63n(int64(max-min)))
+}
@@ -0,0 +1,48 @@
+package osciris
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestHumanMousePathDeterministic(t *testing.T) {
+	from, to := Point{X: 10, Y: 20}, Point{X: 640, Y: 410}
+
+	for _, seed := range []int64{1, 42, 1000} {
+		a := newHumanMouse(&HumanMouseOptions{Seed: seed}).path(from, to)
+		b := newHumanMouse(&HumanMouseOptions{Seed: seed}).path(from, to)
+		if !reflect.DeepEqual(a, b) {
+			t.Fatalf("seed %d: paths differ", seed)
+		}
+		if len(a) == 0 {
+			t.Fatalf("seed %d: empty path", seed)
+		}
+		if last := a[len(a)-1].Point; last != to {
+			t.Fatalf("seed %d: path ends at %v, want %v", seed, last, to)
+		}
+	}
+
+	a := newHumanMouse(&HumanMouseOptions{Seed: 1}).path(from, to)
+	b := newHumanMouse(&HumanMouseOptions{Seed: 2}).path(from, to)
+	if reflect.DeepEqual(a, b) {
+		t.Fatal("different seeds produced the same path")
+	}
+}
+
+func TestHumanMouseOptionsSeedPerTab(t *testing.T) {
+	options := &BrowserOptions{HumanMouse: &HumanMouseOptions{Seed: 42}}
+	parent := &Browser{options: options, seeds: &tabSeeds{}}
+	// Browser вкладки OpenTab/ConnectToTab разделяет счетчик с родителем
+	tab := &Browser{options: options, seeds: parent.seeds}
+
+	var seeds []int64
+	for _, b := range []*Browser{parent, tab, parent} {
+		seeds = append(seeds, b.humanMouseOptions().Seed)
+	}
+	if want := []int64{42, 43, 44}; !reflect.DeepEqual(seeds, want) {
+		t.Errorf("seeds = %v, want %v", seeds, want)
+	}
+	if options.HumanMouse.Seed != 42 {
+		t.Errorf("options seed changed to %d", options.HumanMouse.Seed)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"math/rand"
+	"sync"
 	"time"
 
 	"github.com/chromedp/chromedp"
@@ -27,6 +28,23 @@ type Browser struct {
 	allocCtx    context.Context
 	allocCancel context.CancelFunc
 	isRemote    bool
+	seeds       *tabSeeds
+
+	// page страница собственной вкладки Browser
+	// pages вкладки, открытые через NewTab/AttachTab
+	// ownedContext browser context, созданный для вкладки OpenTabWithProfile и удаляемый вместе с ней
+	mu           sync.Mutex
+	page         *Page
+	pages        []*Page
+	ownedContext cdp.BrowserContextID
+
+	// sessionCtx постоянная CDP-сессия уровня браузера для управления вкладками
+	// devtools собственное соединение для flat-сессий дочерних целей; endpoint адрес DevTools локального Chrome
+	sessionMu     sync.Mutex
+	sessionCtx    context.Context
+	sessionCancel context.CancelFunc
+	devtools      *devtoolsConn
+	endpoint      *devtoolsEndpoint
 }
 
 // Tab представляет вкладку браузера
@@ -36,6 +54,10 @@ type Tab struct {
 	Title    string    `json:"title"`
 	URL      string    `json:"url"`
 	Attached bool      `json:"attached"`
+	// OpenerID ID вкладки, из которой была открыта эта вкладка (пусто, если вкладка открыта не со страницы)
+	OpenerID target.ID `json:"openerId,omitempty"`
+	// BrowserContextID ID browser context вкладки (пусто для контекста по умолчанию)
+	BrowserContextID cdp.BrowserContextID `json:"browserContextId,omitempty"`
 }
 
 // BrowserOptions содержит опции для создания браузера
@@ -55,9 +77,22 @@ type BrowserOptions struct {
 	// Timeout для операций
 	Timeout time.Duration
 
-	// Дополнительные флаги Chrome
+	// Дополнительные флаги Chrome в формате "name", "--name" или "--name=value"
 	Flags []string
 
+	// FlagValues флаги Chrome со значениями: строка или число - "--name=value", true - "--name", false - флаг не передается
+	FlagValues map[string]interface{}
+
+	// RemoveFlags флаги, которые не нужно передавать Chrome, в том числе флаги по умолчанию
+	// (например, "headless", "no-sandbox", "enable-automation")
+	RemoveFlags []string
+
+	// ExecPath путь к исполняемому файлу Chrome (по умолчанию chromedp ищет его сам)
+	ExecPath string
+
+	// Env дополнительные переменные окружения процесса Chrome в формате "KEY=VALUE"
+	Env []string
+
 	// Window размеры
 	WindowWidth  int
 	WindowHeight int
@@ -69,6 +104,28 @@ type BrowserOptions struct {
 	// TargetID ID существующей вкладки для подключения
 	// Если указан, будет подключение к существующей вкладке вместо создания новой
 	TargetID target.ID
+
+	// Block политика блокировки запросов (типы ресурсов, трекеры, аналитика)
+	// Применяется ко всем вкладкам, как и Fingerprint
+	Block *BlockPolicy
+
+	// Proxy прокси для локального браузера (--proxy-server)
+	// Авторизация на прокси выполняется через Fetch domain и для локального, и для удаленного браузера
+	Proxy *Proxy
+
+	// Geolocation координаты, эмулируемые во всех вкладках вместе с разрешением на геолокацию (nil - не эмулируется)
+	// Часовой пояс, локаль и Accept-Language эмулируются по Fingerprint без дополнительных опций
+	Geolocation *Geolocation
+
+	// HumanMouse включает во всех вкладках человекоподобное движение мыши (см. Page.EnableHumanMouse)
+	HumanMouse *HumanMouseOptions
+
+	// Typing настройки человекоподобного ввода текста через SendKeysChar во всех вкладках (nil - по умолчанию)
+	Typing *TypingOptions
+
+	// Profile личность, применяемая к браузеру: заменяет Fingerprint, Proxy и Typing, эмулирует геолокацию,
+	// часовой пояс и локаль во всех вкладках и восстанавливает сессию в первой вкладке
+	Profile *Profile
 }
 
 // DefaultBrowserOptions возвращает опции по умолчанию
@@ -88,10 +145,14 @@ func NewBrowser(ctx context.Context, options *BrowserOptions) (*Browser, error)
 	if options == nil {
 		options = DefaultBrowserOptions()
 	}
+	if options.Profile != nil {
+		options = withProfile(options, options.Profile)
+	}
 
 	var allocCtx context.Context
 	var allocCancel context.CancelFunc
 	var isRemote bool
+	var endpoint *devtoolsEndpoint
 
 	// Проверяем, используется ли удаленный браузер
 	if options.RemoteURL != "" {
@@ -119,11 +180,28 @@ func NewBrowser(ctx context.Context, options *BrowserOptions) (*Browser, error)
 			opts = append(opts, chromedp.UserDataDir(options.UserDataDir))
 		}
 
-		// Добавляем пользовательские флаги
-		for _, flag := range options.Flags {
-			opts = append(opts, chromedp.Flag(flag, ""))
+		if options.Proxy != nil {
+			opts = append(opts, chromedp.ProxyServer(options.Proxy.Server()))
+			if len(options.Proxy.Bypass) > 0 {
+				opts = append(opts, chromedp.Flag("proxy-bypass-list", options.Proxy.BypassList()))
+			}
+		}
+
+		if options.ExecPath != "" {
+			opts = append(opts, chromedp.ExecPath(options.ExecPath))
 		}
 
+		if len(options.Env) > 0 {
+			opts = append(opts, chromedp.Env(options.Env...))
+		}
+
+		// Добавляем пользовательские флаги
+		opts = append(opts, flagOptions(options)...)
+
+		// Адрес DevTools нужен собственному соединению дочерних целей, chromedp его не раскрывает
+		endpoint = &devtoolsEndpoint{}
+		opts = append(opts, chromedp.CombinedOutput(endpoint))
+
 		// Создаем allocator context
 		allocCtx, allocCancel = chromedp.NewExecAllocator(ctx, opts...)
 		isRemote = false
@@ -133,9 +211,27 @@ func NewBrowser(ctx context.Context, options *BrowserOptions) (*Browser, error)
 	var browserCtx context.Context
 	var browserCancel context.CancelFunc
 
+	// Прокси профиля в удаленном браузере задается отдельным browser context, флаг запуска недоступен
+	profileProxy := isRemote && options.Profile != nil && options.Profile.Proxy != nil
+
 	if options.TargetID != "" {
+		if profileProxy {
+			allocCancel()
+			return nil, fmt.Errorf("profile proxy can not be applied to existing tab %s", options.TargetID)
+		}
 		// Подключаемся к существующей вкладке
 		browserCtx, browserCancel = chromedp.NewContext(allocCtx, chromedp.WithTargetID(options.TargetID))
+	} else if profileProxy {
+		proxy := options.Profile.Proxy
+		browserCtx, browserCancel = chromedp.NewContext(allocCtx, chromedp.WithNewBrowserContext(
+			func(params *target.CreateBrowserContextParams) *target.CreateBrowserContextParams {
+				params = params.WithProxyServer(proxy.Server())
+				if len(proxy.Bypass) > 0 {
+					params = params.WithProxyBypassList(proxy.BypassList())
+				}
+				return params
+			},
+		))
 	} else {
 		// Создаем новую вкладку
 		browserCtx, browserCancel = chromedp.NewContext(allocCtx, chromedp.WithLogf(func(format string, v ...interface{}) {
@@ -157,6 +253,15 @@ func NewBrowser(ctx context.Context, options *BrowserOptions) (*Browser, error)
 		injector:    injector,
 		options:     options,
 		isRemote:    isRemote,
+		seeds:       &tabSeeds{},
+		endpoint:    endpoint,
+	}
+
+	// Подключаемся к вкладке до применения fingerprint: первый Run запускает браузер,
+	// поэтому он выполняется без таймаута, иначе отмена таймаута завершит браузер
+	if err := chromedp.Run(browserCtx); err != nil {
+		browser.Close()
+		return nil, fmt.Errorf("failed to attach to tab: %w", err)
 	}
 
 	// Применяем fingerprint при создании
@@ -171,6 +276,20 @@ func NewBrowser(ctx context.Context, options *BrowserOptions) (*Browser, error)
 		}
 	}
 
+	// Применяем сетевые настройки вкладки (блокировка, авторизация прокси) и профиль
+	if err := browser.applyPageOptions(browser.NewPage()); err != nil {
+		browser.Close()
+		return nil, err
+	}
+
+	// Сессию профиля восстанавливаем только в новой вкладке, чтобы не уводить существующую со страницы
+	if options.TargetID == "" {
+		if err := browser.restoreProfileSession(browser.NewPage()); err != nil {
+			browser.Close()
+			return nil, err
+		}
+	}
+
 	return browser, nil
 }
 
@@ -191,6 +310,9 @@ func NewRemoteBrowserManager(ctx context.Context, remoteURL string, options *Bro
 		options = DefaultBrowserOptions()
 	}
 	options.RemoteURL = remoteURL
+	if options.Profile != nil {
+		options = withProfile(options, options.Profile)
+	}
 	
 	var allocCtx context.Context
 	var allocCancel context.CancelFunc
@@ -198,10 +320,9 @@ func NewRemoteBrowserManager(ctx context.Context, remoteURL string, options *Bro
 	// Используем удаленный allocator
 	allocCtx, allocCancel = chromedp.NewRemoteAllocator(ctx, remoteURL)
 	
-	// НЕ создаем chromedp контекст, чтобы не создавать новую вкладку
-	// Вместо этого используем allocCtx напрямую для выполнения CDP команд
-	// Создаем фиктивный контекст, который не будет использоваться для операций со страницей
-	browserCtx, browserCancel := context.WithCancel(allocCtx)
+	// Создаем chromedp контекст, но НЕ выполняем в нем Run, чтобы не создавать новую вкладку
+	// Контекст служит родителем для вкладок, открываемых через NewTab/AttachTab
+	browserCtx, browserCancel := chromedp.NewContext(allocCtx)
 	
 	// Создаем инжектор fingerprint (но не применяем его, так как нет активной вкладки)
 	var injector *fp.Injector
@@ -217,6 +338,7 @@ func NewRemoteBrowserManager(ctx context.Context, remoteURL string, options *Bro
 		injector:    injector,
 		options:     options,
 		isRemote:    true,
+		seeds:       &tabSeeds{},
 	}
 	
 	return browser, nil
@@ -229,6 +351,8 @@ func (b *Browser) Context() context.Context {
 
 // Close закрывает браузер и освобождает ресурсы
 func (b *Browser) Close() error {
+	b.disposeOwnedContext()
+	b.closeSession()
 	if b.cancel != nil {
 		b.cancel()
 	}
@@ -239,50 +363,22 @@ func (b *Browser) Close() error {
 	return nil
 }
 
-// CloseTab закрывает текущую вкладку (только для удаленного браузера)
+// CloseTab закрывает вкладку, к которой привязан Browser
 func (b *Browser) CloseTab() error {
-	if !b.isRemote {
-		return fmt.Errorf("CloseTab can only be used with remote browser")
-	}
-
-	// Для удаленного браузера создаем временный контекст из allocCtx
-	tempCtx, tempCancel := chromedp.NewContext(b.allocCtx)
-	defer tempCancel()
-
-	timeoutCtx, cancel := context.WithTimeout(tempCtx, b.options.Timeout)
-	defer cancel()
-
-	// Получаем target ID текущей вкладки через список всех вкладок
-	var targetID target.ID
-	err := chromedp.Run(timeoutCtx, chromedp.ActionFunc(func(ctx context.Context) error {
-		targets, err := target.GetTargets().Do(ctx)
-		if err != nil {
-			return err
-		}
-		// Находим прикрепленную вкладку
-		for _, t := range targets {
-			if t.Attached && t.Type == "page" {
-				targetID = t.TargetID
-				return nil
-			}
-		}
-		return fmt.Errorf("target ID not found")
-	}))
-	if err != nil {
-		return fmt.Errorf("failed to get target ID: %w", err)
-	}
-
+	targetID := b.GetTargetID()
 	if targetID == "" {
 		return fmt.Errorf("target ID not found")
 	}
 
 	// Закрываем вкладку через CDP
-	err = chromedp.Run(timeoutCtx, chromedp.ActionFunc(func(ctx context.Context) error {
+	err := b.runBrowser(func(ctx context.Context) error {
 		return target.CloseTarget(targetID).Do(ctx)
-	}))
+	})
 	if err != nil {
 		return fmt.Errorf("failed to close tab: %w", err)
 	}
+	b.disposeOwnedContext()
+	b.closeSession()
 
 	// Закрываем context вкладки
 	if b.cancel != nil {
@@ -293,29 +389,19 @@ func (b *Browser) CloseTab() error {
 }
 
 // CloseTabByID закрывает вкладку по её ID
-// Использует временный контекст для закрытия вкладки через CDP
+// Команда выполняется через CDP-сессию браузера, не привязанную к закрываемой вкладке
 func (b *Browser) CloseTabByID(targetID target.ID) error {
 	if !b.isRemote {
 		return fmt.Errorf("CloseTabByID can only be used with remote browser")
 	}
 
-	// Создаем временный контекст для выполнения команды закрытия
-	// Этот контекст создаст новую временную вкладку, но мы её не используем
-	tempCtx, tempCancel := chromedp.NewContext(b.allocCtx)
-	defer tempCancel()
-
-	timeoutCtx, cancel := context.WithTimeout(tempCtx, b.options.Timeout)
-	defer cancel()
-
-	// Закрываем вкладку через CDP команду CloseTarget
-	// Используем временный контекст, который не привязан к закрываемой вкладке
-	err := chromedp.Run(timeoutCtx, chromedp.ActionFunc(func(ctx context.Context) error {
+	err := b.runBrowser(func(ctx context.Context) error {
 		// Проверяем, что вкладка существует
 		targets, err := target.GetTargets().Do(ctx)
 		if err != nil {
 			return err
 		}
-		
+
 		found := false
 		for _, t := range targets {
 			if t.TargetID == targetID {
@@ -323,16 +409,14 @@ func (b *Browser) CloseTabByID(targetID target.ID) error {
 				break
 			}
 		}
-		
+
 		if !found {
 			return fmt.Errorf("target %s not found", targetID)
 		}
-		
-		// Закрываем вкладку через CloseTarget
-		// Важно: это работает только если к вкладке не прикреплен активный контекст chromedp
+
 		return target.CloseTarget(targetID).Do(ctx)
-	}))
-	
+	})
+
 	if err != nil {
 		return fmt.Errorf("failed to close tab: %w", err)
 	}
@@ -353,24 +437,70 @@ func (b *Browser) Run(actions ...chromedp.Action) error {
 type Page struct {
 	browser *Browser
 	ctx     context.Context
+	// cancel закрывает вкладку страницы (nil для страницы, привязанной к контексту Browser)
+	cancel context.CancelFunc
+	// proxy прокси изолированной вкладки (nil - используется BrowserOptions.Proxy)
+	proxy *Proxy
+	// context BrowserContext, в котором открыта вкладка (nil - контекст по умолчанию)
+	context *BrowserContext
+
+	// router распределяет запросы, перехваченные через Fetch domain
+	// har записывает запросы страницы в HAR
+	// device эмулируемое устройство (nil - эмуляция не включена), userAgentDataScript скрипт, скрывающий navigator.userAgentData
+	// mouse генератор траекторий курсора (nil - человекоподобное движение выключено), cursor последняя позиция курсора
+	// typist модель печати SendKeysChar (создается при первом вводе)
+	mu                  sync.Mutex
+	router              *fetchRouter
+	har                 *harRecorder
+	device              *Device
+	userAgentDataScript page.ScriptIdentifier
+	mouse               *humanMouse
+	cursor              Point
+	cursorKnown         bool
+	typist              *typist
 }
 
 // NewPage создает новую страницу
+// Страница привязана к вкладке самого Browser; для отдельных вкладок используйте NewTab
 func (b *Browser) NewPage() *Page {
-	return &Page{
-		browser: b,
-		ctx:     b.ctx,
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	// Страница создается один раз, чтобы перехват запросов вкладки управлялся из одного места
+	if b.page == nil {
+		b.page = &Page{
+			browser: b,
+			ctx:     b.ctx,
+		}
 	}
+	return b.page
+}
+
+// run выполняет действия в контексте вкладки страницы
+func (p *Page) run(actions ...chromedp.Action) error {
+	timeoutCtx, cancel := context.WithTimeout(p.ctx, p.browser.options.Timeout)
+	defer cancel()
+	return chromedp.Run(timeoutCtx, chromedp.Tasks(actions))
+}
+
+// Context возвращает context вкладки страницы
+func (p *Page) Context() context.Context {
+	return p.ctx
+}
+
+// Browser возвращает браузер, которому принадлежит страница
+func (p *Page) Browser() *Browser {
+	return p.browser
 }
 
 // Navigate переходит по URL
 func (p *Page) Navigate(url string) error {
-	return p.browser.Run(chromedp.Navigate(url))
+	return p.run(chromedp.Navigate(url))
 }
 
 // NavigateAndWait переходит по URL и ждет загрузки
 func (p *Page) NavigateAndWait(url string, waitVisible string) error {
-	return p.browser.Run(
+	return p.run(
 		chromedp.Navigate(url),
 		chromedp.WaitVisible(waitVisible),
 	)
@@ -378,87 +508,99 @@ func (p *Page) NavigateAndWait(url string, waitVisible string) error {
 
 // WaitVisible ждет появления элемента
 func (p *Page) WaitVisible(selector string) error {
-	return p.browser.Run(chromedp.WaitVisible(selector))
+	return p.run(chromedp.WaitVisible(selector))
 }
 
 // Click кликает по элементу
 func (p *Page) Click(selector string) error {
-	return p.browser.Run(chromedp.Click(selector))
+	if p.touch() {
+		return p.run(tapSelector(selector))
+	}
+	if m := p.humanMouse(); m != nil {
+		return p.run(p.humanClickSelector(m, selector, 0))
+	}
+	return p.run(chromedp.Click(selector))
 }
 
 // SendKeys отправляет текст в элемент
 func (p *Page) SendKeys(selector, text string) error {
-	return p.browser.Run(chromedp.SendKeys(selector, text))
+	return p.run(chromedp.SendKeys(selector, text))
 }
 
 // Value получает значение элемента
 func (p *Page) Value(selector string, result *string) error {
-	return p.browser.Run(chromedp.Value(selector, result))
+	return p.run(chromedp.Value(selector, result))
 }
 
 // Text получает текст элемента
 func (p *Page) Text(selector string, result *string) error {
-	return p.browser.Run(chromedp.Text(selector, result))
+	return p.run(chromedp.Text(selector, result))
 }
 
 // Screenshot делает скриншот страницы
 func (p *Page) Screenshot(buf *[]byte) error {
-	return p.browser.Run(chromedp.CaptureScreenshot(buf))
+	return p.run(chromedp.CaptureScreenshot(buf))
 }
 
 // Evaluate выполняет JavaScript и возвращает результат
 func (p *Page) Evaluate(expression string, result interface{}) error {
-	return p.browser.Run(chromedp.Evaluate(expression, result))
+	return p.run(chromedp.Evaluate(expression, result))
 }
 
 // Reload перезагружает страницу
 func (p *Page) Reload() error {
-	return p.browser.Run(chromedp.Reload())
+	return p.run(chromedp.Reload())
 }
 
 // Back возвращается назад
 func (p *Page) Back() error {
-	return p.browser.Run(chromedp.NavigateBack())
+	return p.run(chromedp.NavigateBack())
 }
 
 // Forward переходит вперед
 func (p *Page) Forward() error {
-	return p.browser.Run(chromedp.NavigateForward())
+	return p.run(chromedp.NavigateForward())
 }
 
 // Title получает заголовок страницы
 func (p *Page) Title(result *string) error {
-	return p.browser.Run(chromedp.Title(result))
+	return p.run(chromedp.Title(result))
 }
 
 // URL получает текущий URL
 func (p *Page) URL(result *string) error {
-	return p.browser.Run(chromedp.Location(result))
+	return p.run(chromedp.Location(result))
 }
 
 // RunActions выполняет произвольные действия chromedp
 func (p *Page) RunActions(actions ...chromedp.Action) error {
-	return p.browser.Run(actions...)
+	return p.run(actions...)
 }
 
 // WaitReady ждет готовности элемента
 func (p *Page) WaitReady(selector string) error {
-	return p.browser.Run(chromedp.WaitReady(selector))
+	return p.run(chromedp.WaitReady(selector))
 }
 
 // Focus устанавливает фокус на элемент
 func (p *Page) Focus(selector string) error {
-	return p.browser.Run(chromedp.Focus(selector))
+	return p.run(chromedp.Focus(selector))
 }
 
 // ScrollIntoView прокручивает страницу к элементу
 func (p *Page) ScrollIntoView(selector string) error {
-	return p.browser.Run(chromedp.ScrollIntoView(selector))
+	return p.run(chromedp.ScrollIntoView(selector))
 }
 
 // ClickWithScroll прокручивает к элементу и кликает по нему
 func (p *Page) ClickWithScroll(selector string) error {
-	return p.browser.Run(
+	if p.touch() {
+		return p.run(tapSelector(selector))
+	}
+	if m := p.humanMouse(); m != nil {
+		return p.run(p.humanClickSelector(m, selector, 0))
+	}
+	return p.run(
 		chromedp.ScrollIntoView(selector),
 		chromedp.Sleep(500*time.Millisecond),
 		chromedp.Click(selector),
@@ -467,50 +609,43 @@ func (p *Page) ClickWithScroll(selector string) error {
 
 // ClickXY кликает по координатам
 func (p *Page) ClickXY(x, y float64) error {
-	return p.browser.Run(chromedp.MouseClickXY(x, y))
+	if p.touch() {
+		return p.TapXY(x, y)
+	}
+	if p.humanMouse() != nil {
+		return p.MouseClick(x, y, input.Left)
+	}
+	p.setCursor(x, y)
+	return p.run(chromedp.MouseClickXY(x, y))
 }
 
 // KeyEvent отправляет событие нажатия клавиши
 func (p *Page) KeyEvent(key string) error {
-	return p.browser.Run(chromedp.KeyEvent(key))
-}
-
-// SendKeysChar отправляет текст посимвольно (имитация человеческого ввода)
-func (p *Page) SendKeysChar(selector, text string) error {
-	for _, char := range text {
-		err := p.browser.Run(chromedp.SendKeys(selector, string(char)))
-		if err != nil {
-			return err
-		}
-		// Случайная задержка между символами (50-150ms)
-		delay := time.Duration(50+rand.Intn(100)) * time.Millisecond
-		time.Sleep(delay)
-	}
-	return nil
+	return p.run(chromedp.KeyEvent(key))
 }
 
 // SendKeysEnter отправляет Enter в элемент
 func (p *Page) SendKeysEnter(selector string) error {
-	return p.browser.Run(chromedp.SendKeys(selector, kb.Enter))
+	return p.run(chromedp.SendKeys(selector, kb.Enter))
 }
 
 // Nodes получает список узлов DOM по селектору
 func (p *Page) Nodes(selector string) ([]*cdp.Node, error) {
 	var nodes []*cdp.Node
-	err := p.browser.Run(chromedp.Nodes(selector, &nodes))
+	err := p.run(chromedp.Nodes(selector, &nodes))
 	return nodes, err
 }
 
 // NodesAll получает все узлы DOM по селектору
 func (p *Page) NodesAll(selector string) ([]*cdp.Node, error) {
 	var nodes []*cdp.Node
-	err := p.browser.Run(chromedp.Nodes(selector, &nodes, chromedp.ByQueryAll))
+	err := p.run(chromedp.Nodes(selector, &nodes, chromedp.ByQueryAll))
 	return nodes, err
 }
 
 // ClearInput очищает поле ввода
 func (p *Page) ClearInput(selector string) error {
-	return p.browser.Run(
+	return p.run(
 		chromedp.Focus(selector),
 		chromedp.Evaluate(fmt.Sprintf(`document.querySelector('%s').value = '';`, selector), nil),
 	)
@@ -518,14 +653,24 @@ func (p *Page) ClearInput(selector string) error {
 
 // MouseMove перемещает мышь к координатам
 func (p *Page) MouseMove(x, y float64) error {
-	return p.browser.Run(chromedp.ActionFunc(func(ctx context.Context) error {
+	p.setCursor(x, y)
+	return p.run(chromedp.ActionFunc(func(ctx context.Context) error {
 		return input.DispatchMouseEvent(input.MouseMoved, x, y).Do(ctx)
 	}))
 }
 
 // MouseClick выполняет клик мыши по координатам
 func (p *Page) MouseClick(x, y float64, button input.MouseButton) error {
-	return p.browser.Run(
+	if button == input.Left && p.touch() {
+		return p.TapXY(x, y)
+	}
+	if m := p.humanMouse(); m != nil {
+		return p.run(chromedp.ActionFunc(func(ctx context.Context) error {
+			return p.humanClick(ctx, m, Point{X: x, Y: y}, button, 0)
+		}))
+	}
+	p.setCursor(x, y)
+	return p.run(
 		chromedp.ActionFunc(func(ctx context.Context) error {
 			// Нажатие
 			if err := input.DispatchMouseEvent(input.MousePressed, x, y).
@@ -546,13 +691,25 @@ func (p *Page) MouseClick(x, y float64, button input.MouseButton) error {
 
 // MouseClickCtrl выполняет Ctrl+Click по координатам (открытие в новой вкладке)
 func (p *Page) MouseClickCtrl(x, y float64) error {
-	return p.browser.Run(
+	if m := p.humanMouse(); m != nil {
+		return p.run(chromedp.ActionFunc(func(ctx context.Context) error {
+			if err := p.humanClick(ctx, m, Point{X: x, Y: y}, input.Left, input.ModifierCtrl); err != nil {
+				return err
+			}
+			// Задержка для открытия новой вкладки
+			return sleep(ctx, 300*time.Millisecond)
+		}))
+	}
+	p.setCursor(x, y)
+	return p.run(
 		chromedp.ActionFunc(func(ctx context.Context) error {
 			// Сначала перемещаем мышь к элементу
 			if err := input.DispatchMouseEvent(input.MouseMoved, x, y).Do(ctx); err != nil {
 				return err
 			}
-			time.Sleep(50 * time.Millisecond)
+			if err := sleep(ctx, 50*time.Millisecond); err != nil {
+				return err
+			}
 			
 			// Нажатие с Ctrl
 			if err := input.DispatchMouseEvent(input.MousePressed, x, y).
@@ -562,7 +719,10 @@ func (p *Page) MouseClickCtrl(x, y float64) error {
 				Do(ctx); err != nil {
 				return err
 			}
-			time.Sleep(100 * time.Millisecond) // Увеличенная задержка для надежности
+			// Увеличенная задержка для надежности
+			if err := sleep(ctx, 100*time.Millisecond); err != nil {
+				return err
+			}
 			
 			// Отпускание с Ctrl
 			if err := input.DispatchMouseEvent(input.MouseReleased, x, y).
@@ -574,15 +734,14 @@ func (p *Page) MouseClickCtrl(x, y float64) error {
 			}
 			
 			// Дополнительная задержка для открытия новой вкладки
-			time.Sleep(300 * time.Millisecond)
-			return nil
+			return sleep(ctx, 300*time.Millisecond)
 		}),
 	)
 }
 
 // MouseWheel прокручивает колесом мыши
 func (p *Page) MouseWheel(x, y, deltaY float64) error {
-	return p.browser.Run(chromedp.ActionFunc(func(ctx context.Context) error {
+	return p.run(chromedp.ActionFunc(func(ctx context.Context) error {
 		return input.DispatchMouseEvent(input.MouseWheel, x, y).
 			WithDeltaY(deltaY).
 			Do(ctx)
@@ -592,13 +751,13 @@ func (p *Page) MouseWheel(x, y, deltaY float64) error {
 // GetElementBox получает координаты элемента
 func (p *Page) GetElementBox(selector string) (*dom.BoxModel, error) {
 	var nodes []*cdp.Node
-	err := p.browser.Run(chromedp.Nodes(selector, &nodes))
+	err := p.run(chromedp.Nodes(selector, &nodes))
 	if err != nil || len(nodes) == 0 {
 		return nil, fmt.Errorf("element not found: %s", selector)
 	}
 
 	var box *dom.BoxModel
-	err = p.browser.Run(chromedp.ActionFunc(func(ctx context.Context) error {
+	err = p.run(chromedp.ActionFunc(func(ctx context.Context) error {
 		var err error
 		box, err = dom.GetBoxModel().WithNodeID(nodes[0].NodeID).Do(ctx)
 		return err
@@ -616,6 +775,10 @@ func (p *Page) ClickElementWithCtrl(selector string) error {
 	// Вычисляем центр элемента
 	x := (box.Content[0] + box.Content[2]) / 2
 	y := (box.Content[1] + box.Content[5]) / 2
+	if m := p.humanMouse(); m != nil {
+		point := m.pointIn(box.Content)
+		x, y = point.X, point.Y
+	}
 
 	return p.MouseClickCtrl(x, y)
 }
@@ -633,7 +796,7 @@ func (p *Page) ClickOnNewTab(selector string) error {
 	time.Sleep(300 * time.Millisecond)
 	
 	// Дополнительно прокручиваем так, чтобы элемент был в центре видимой области
-	err = p.browser.Run(chromedp.ActionFunc(func(ctx context.Context) error {
+	err = p.run(chromedp.ActionFunc(func(ctx context.Context) error {
 		// Получаем box model для более точной прокрутки
 		var nodes []*cdp.Node
 		if err := chromedp.Nodes(selector, &nodes).Do(ctx); err != nil || len(nodes) == 0 {
@@ -670,31 +833,30 @@ func (p *Page) ClickOnNewTab(selector string) error {
 	// Вычисляем центр элемента
 	x := (box.Content[0] + box.Content[2]) / 2
 	y := (box.Content[1] + box.Content[5]) / 2
+	if m := p.humanMouse(); m != nil {
+		point := m.pointIn(box.Content)
+		x, y = point.X, point.Y
+	}
 
 	// Выполняем Ctrl+Click
 	return p.MouseClickCtrl(x, y)
 }
 
-// HumanMouseMove имитирует человеческое движение мыши
+// HumanMouseMove ведет курсор к координатам по человекоподобной траектории от его последней позиции
+// Использует настройки EnableHumanMouse, если режим включен
 func (p *Page) HumanMouseMove(x, y float64) error {
-	return p.browser.Run(chromedp.ActionFunc(func(ctx context.Context) error {
-		// Случайное движение с небольшими отклонениями
-		offsetX := float64(rand.Intn(10) - 5)
-		offsetY := float64(rand.Intn(10) - 5)
-		
-		if err := input.DispatchMouseEvent(input.MouseMoved, x+offsetX, y+offsetY).Do(ctx); err != nil {
-			return err
-		}
-		
-		time.Sleep(time.Duration(30+rand.Intn(120)) * time.Millisecond)
-		
-		return input.DispatchMouseEvent(input.MouseMoved, x, y).Do(ctx)
+	m := p.humanMouse()
+	if m == nil {
+		m = newHumanMouse(nil)
+	}
+	return p.run(chromedp.ActionFunc(func(ctx context.Context) error {
+		return p.humanMove(ctx, m, Point{X: x, Y: y})
 	}))
 }
 
 // HumanScroll имитирует человеческую прокрутку
 func (p *Page) HumanScroll(x, y float64) error {
-	return p.browser.Run(chromedp.ActionFunc(func(ctx context.Context) error {
+	return p.run(chromedp.ActionFunc(func(ctx context.Context) error {
 		deltaY := float64(50 - rand.Intn(100))
 		return input.DispatchMouseEvent(input.MouseWheel, x, y).
 			WithDeltaY(deltaY).
@@ -702,25 +864,22 @@ func (p *Page) HumanScroll(x, y float64) error {
 	}))
 }
 
-// SetUserAgent устанавливает User-Agent и платформу
+// SetUserAgent устанавливает User-Agent, платформу и согласованные с User-Agent client hints
+// Accept-Language вкладки сохраняется
 func (p *Page) SetUserAgent(userAgent, platform string) error {
-	return p.browser.Run(chromedp.ActionFunc(func(ctx context.Context) error {
-		return emulation.SetUserAgentOverride(userAgent).
-			WithPlatform(platform).
-			Do(ctx)
-	}))
+	return p.run(userAgentOverride(userAgent, platform, p.browser.pageEmulation(p).acceptLanguage()))
 }
 
 // SetViewport устанавливает размеры окна просмотра
 func (p *Page) SetViewport(width, height int64, mobile bool) error {
-	return p.browser.Run(chromedp.ActionFunc(func(ctx context.Context) error {
+	return p.run(chromedp.ActionFunc(func(ctx context.Context) error {
 		return emulation.SetDeviceMetricsOverride(width, height, 1.0, mobile).Do(ctx)
 	}))
 }
 
 // SetGeolocation устанавливает геолокацию
 func (p *Page) SetGeolocation(lat, lng float64) error {
-	return p.browser.Run(chromedp.ActionFunc(func(ctx context.Context) error {
+	return p.run(chromedp.ActionFunc(func(ctx context.Context) error {
 		return emulation.SetGeolocationOverride().
 			WithLatitude(lat).
 			WithLongitude(lng).
@@ -730,7 +889,7 @@ func (p *Page) SetGeolocation(lat, lng float64) error {
 
 // AddScriptToEvaluateOnNewDocument добавляет скрипт, который выполняется на каждой новой странице
 func (p *Page) AddScriptToEvaluateOnNewDocument(jsCode string) error {
-	return p.browser.Run(chromedp.ActionFunc(func(ctx context.Context) error {
+	return p.run(chromedp.ActionFunc(func(ctx context.Context) error {
 		_, err := page.AddScriptToEvaluateOnNewDocument(jsCode).Do(ctx)
 		if err != nil {
 			return err
@@ -744,7 +903,7 @@ func (p *Page) AddScriptToEvaluateOnNewDocument(jsCode string) error {
 // ReadyState получает состояние готовности страницы
 func (p *Page) ReadyState() (string, error) {
 	var readyState string
-	err := p.browser.Run(chromedp.Evaluate(`document.readyState`, &readyState))
+	err := p.run(chromedp.Evaluate(`document.readyState`, &readyState))
 	return readyState, err
 }
 
@@ -817,7 +976,7 @@ func (p *Page) FastCheckElement(selector string) bool {
 
 // ScrollPage прокручивает страницу для загрузки всех элементов
 func (p *Page) ScrollPage(scrollDownTimes, scrollUpTimes int) error {
-	return p.browser.Run(chromedp.ActionFunc(func(ctx context.Context) error {
+	return p.run(chromedp.ActionFunc(func(ctx context.Context) error {
 		// Прокручиваем страницу вниз несколько раз
 		for i := 0; i < scrollDownTimes; i++ {
 			if err := input.DispatchMouseEvent(input.MouseWheel, 0, 0).
@@ -844,7 +1003,7 @@ func (p *Page) ScrollPage(scrollDownTimes, scrollUpTimes int) error {
 // GetElementAttributes получает атрибуты элемента по NodeID
 func (p *Page) GetElementAttributes(nodeID cdp.NodeID) (map[string]string, error) {
 	attrsMap := make(map[string]string)
-	err := p.browser.Run(chromedp.ActionFunc(func(ctx context.Context) error {
+	err := p.run(chromedp.ActionFunc(func(ctx context.Context) error {
 		attrs, err := dom.GetAttributes(nodeID).Do(ctx)
 		if err == nil && len(attrs) > 0 {
 			// Атрибуты возвращаются как массив [name1, value1, name2, value2, ...]
@@ -865,26 +1024,12 @@ func (b *Browser) ListTabs() ([]Tab, error) {
 		return nil, fmt.Errorf("ListTabs can only be used with remote browser")
 	}
 
-	// Для удаленного браузера создаем временный контекст из allocCtx для выполнения CDP команд
-	// Этот контекст создаст временную вкладку, но мы её не используем
-	tempCtx, tempCancel := chromedp.NewContext(b.allocCtx)
-	// НЕ используем defer tempCancel() здесь, чтобы контекст не отменялся преждевременно
-	// Контекст будет отменен после выполнения команды
-
-	timeoutCtx, cancel := context.WithTimeout(tempCtx, b.options.Timeout*2)
-	
-	// Выполняем команду через chromedp.Run для установки соединения
 	var targets []*target.Info
-	err := chromedp.Run(timeoutCtx, chromedp.ActionFunc(func(ctx context.Context) error {
+	err := b.runBrowser(func(ctx context.Context) error {
 		var err error
 		targets, err = target.GetTargets().Do(ctx)
 		return err
-	}))
-	
-	// Отменяем контексты после выполнения команды
-	cancel()
-	tempCancel()
-	
+	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to get targets: %w", err)
 	}
@@ -892,13 +1037,7 @@ func (b *Browser) ListTabs() ([]Tab, error) {
 	tabs := make([]Tab, 0, len(targets))
 	for _, t := range targets {
 		if t.Type == "page" {
-			tabs = append(tabs, Tab{
-				ID:       t.TargetID,
-				Type:     t.Type,
-				Title:    t.Title,
-				URL:      t.URL,
-				Attached: t.Attached,
-			})
+			tabs = append(tabs, tabFromInfo(t))
 		}
 	}
 
@@ -911,59 +1050,101 @@ func (b *Browser) OpenTab(url string) (*Browser, error) {
 		return nil, fmt.Errorf("OpenTab can only be used with remote browser")
 	}
 
-	// Для удаленного браузера создаем временный контекст из allocCtx для выполнения CDP команд
-	// Этот контекст создаст временную вкладку, но мы её не используем
-	tempCtx, tempCancel := chromedp.NewContext(b.allocCtx)
-	// НЕ используем defer tempCancel() здесь, чтобы контекст не отменялся преждевременно
+	return b.openTab(url, b.options, b.injector, "")
+}
 
-	// Используем очень большой timeout для создания вкладки
-	timeoutCtx, cancel := context.WithTimeout(tempCtx, b.options.Timeout*5)
+// OpenTabWithProfile открывает вкладку с профилем в отдельном browser context
+// Fingerprint, прокси, геолокация, часовой пояс, локаль и сессия профиля применяются до перехода на url;
+// browser context удаляется при CloseTab/Close
+func (b *Browser) OpenTabWithProfile(url string, profile *Profile) (*Browser, error) {
+	if profile == nil {
+		return nil, fmt.Errorf("profile is nil")
+	}
 
-	createURL := "about:blank"
-	if url != "" {
-		createURL = url
+	options := withProfile(b.options, profile)
+	injector := b.injector
+	if profile.Fingerprint != nil {
+		injector = fp.NewInjector(profile.Fingerprint)
+	}
+
+	params := target.CreateBrowserContext().WithDisposeOnDetach(true)
+	if options.Proxy != nil {
+		params = params.WithProxyServer(options.Proxy.Server())
+		if len(options.Proxy.Bypass) > 0 {
+			params = params.WithProxyBypassList(options.Proxy.BypassList())
+		}
+	}
+
+	var contextID cdp.BrowserContextID
+	err := b.runBrowser(func(ctx context.Context) error {
+		var err error
+		contextID, err = params.Do(ctx)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create browser context: %w", err)
 	}
 
-	// Создаем новую вкладку через CDP
+	return b.openTab(url, options, injector, contextID)
+}
+
+// openTab создает вкладку в browser context contextID (пусто - контекст по умолчанию) и возвращает привязанный к ней Browser
+func (b *Browser) openTab(url string, options *BrowserOptions, injector *fp.Injector, contextID cdp.BrowserContextID) (*Browser, error) {
+	// Вкладка создается пустой: fingerprint и профиль должны быть применены до загрузки url
 	var targetID target.ID
-	err := chromedp.Run(timeoutCtx, 
-		chromedp.Sleep(1*time.Second), // Увеличенная задержка для установки соединения
-		chromedp.ActionFunc(func(ctx context.Context) error {
-			var err error
-			targetID, err = target.CreateTarget(createURL).Do(ctx)
-			return err
-		}))
-	
-	// Отменяем временные контексты после успешного создания вкладки
-	// Но делаем это с небольшой задержкой, чтобы операции успели завершиться
+	err := b.runBrowser(func(ctx context.Context) error {
+		var err error
+		targetID, err = target.CreateTarget("about:blank").WithBrowserContextID(contextID).Do(ctx)
+		return err
+	})
 	if err != nil {
-		cancel()
-		tempCancel()
+		if contextID != "" {
+			b.runBrowser(target.DisposeBrowserContext(contextID).Do)
+		}
 		return nil, fmt.Errorf("failed to create new tab: %w", err)
 	}
-	
-	// Даем время для завершения операций перед отменой временных контекстов
-	time.Sleep(200 * time.Millisecond)
-	cancel()
-	tempCancel()
 
 	// Подключаемся к новой вкладке
-	tabCtx, tabCancel := chromedp.NewContext(b.allocCtx, chromedp.WithTargetID(targetID))
+	// Локальный allocator запустил бы новый процесс Chrome, поэтому локальная вкладка подключается через сессию браузера
+	parent := b.allocCtx
+	if !b.isRemote {
+		session, err := b.browserSession()
+		if err != nil {
+			if contextID != "" {
+				b.runBrowser(target.DisposeBrowserContext(contextID).Do)
+			}
+			return nil, fmt.Errorf("failed to attach to tab: %w", err)
+		}
+		parent = session
+	}
+	tabCtx, tabCancel := chromedp.NewContext(parent, chromedp.WithTargetID(targetID))
+
+	// Первый Run устанавливает соединение, поэтому выполняется без таймаута
+	if err := chromedp.Run(tabCtx); err != nil {
+		tabCancel()
+		if contextID != "" {
+			b.runBrowser(target.DisposeBrowserContext(contextID).Do)
+		}
+		return nil, fmt.Errorf("failed to attach to tab: %w", err)
+	}
 
 	// Создаем новый Browser для вкладки
 	newBrowser := &Browser{
-		ctx:         tabCtx,
-		cancel:      tabCancel,
-		allocCtx:    b.allocCtx,
-		allocCancel: nil, // Не закрываем allocator, он общий
-		injector:    b.injector,
-		options:     b.options,
-		isRemote:    true,
+		ctx:          tabCtx,
+		cancel:       tabCancel,
+		allocCtx:     b.allocCtx,
+		allocCancel:  nil, // Не закрываем allocator, он общий
+		injector:     injector,
+		options:      options,
+		isRemote:     b.isRemote,
+		seeds:        b.seeds,
+		ownedContext: contextID,
+		endpoint:     b.endpoint,
 	}
 
 	// Применяем fingerprint
 	if newBrowser.injector != nil {
-		fpCtx, fpCancel := context.WithTimeout(tabCtx, b.options.Timeout)
+		fpCtx, fpCancel := context.WithTimeout(tabCtx, options.Timeout)
 		defer fpCancel()
 
 		err := chromedp.Run(fpCtx, newBrowser.injector.ApplyAll(fpCtx))
@@ -973,6 +1154,20 @@ func (b *Browser) OpenTab(url string) (*Browser, error) {
 		}
 	}
 
+	// Применяем сетевые настройки вкладки (блокировка, авторизация прокси) и профиль
+	if err := newBrowser.applyPageOptions(newBrowser.NewPage()); err != nil {
+		newBrowser.Close()
+		return nil, err
+	}
+
+	// Сессия восстанавливается только в собственном browser context, чтобы не перезаписать cookies других вкладок
+	if contextID != "" {
+		if err := newBrowser.restoreProfileSession(newBrowser.NewPage()); err != nil {
+			newBrowser.Close()
+			return nil, err
+		}
+	}
+
 	// Если URL был указан, переходим на него
 	if url != "" {
 		err = chromedp.Run(tabCtx, chromedp.Navigate(url))
@@ -1003,6 +1198,7 @@ func (b *Browser) ConnectToTab(targetID target.ID) (*Browser, error) {
 		injector:    b.injector,
 		options:     b.options,
 		isRemote:    true,
+		seeds:       b.seeds,
 	}
 
 	// Устанавливаем соединение с вкладкой
@@ -1041,38 +1237,20 @@ func (b *Browser) ConnectToTab(targetID target.ID) (*Browser, error) {
 		}
 	}
 
+	// Применяем сетевые настройки вкладки (блокировка, авторизация прокси)
+	if err := newBrowser.applyPageOptions(newBrowser.NewPage()); err != nil {
+		newBrowser.Close()
+		return nil, err
+	}
+
 	return newBrowser, nil
 }
 
-// GetTargetID возвращает ID текущей вкладки
+// GetTargetID возвращает ID вкладки, к которой привязан Browser
+// Для Browser без собственной вкладки (NewRemoteBrowserManager) возвращает пустую строку
 func (b *Browser) GetTargetID() target.ID {
-	if !b.isRemote {
-		return ""
+	if c := chromedp.FromContext(b.ctx); c != nil && c.Target != nil {
+		return c.Target.TargetID
 	}
-
-	// Для удаленного браузера создаем временный контекст из allocCtx
-	tempCtx, tempCancel := chromedp.NewContext(b.allocCtx)
-	defer tempCancel()
-
-	timeoutCtx, cancel := context.WithTimeout(tempCtx, b.options.Timeout)
-	defer cancel()
-
-	var targetID target.ID
-	chromedp.Run(timeoutCtx, chromedp.ActionFunc(func(ctx context.Context) error {
-		targets, err := target.GetTargets().Do(ctx)
-		if err != nil {
-			return err
-		}
-		// Находим текущую вкладку (прикрепленную)
-		for _, t := range targets {
-			if t.Attached && t.Type == "page" {
-				targetID = t.TargetID
-				return nil
-			}
-		}
-		return nil
-	}))
-
-	return targetID
+	return ""
 }
-
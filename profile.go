@@ -0,0 +1,202 @@
+package osciris
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/chromedp/cdproto/target"
+	fp "github.com/vitaliitsarov/fingerprint-injector-go"
+)
+
+// Profile личность: fingerprint, прокси, сессия и параметры окружения
+// Применяется через BrowserOptions.Profile или Browser.OpenTabWithProfile
+type Profile struct {
+	ID string `json:"id"`
+
+	// Fingerprint заменяет BrowserOptions.Fingerprint
+	Fingerprint *fp.Fingerprint `json:"fingerprint,omitempty"`
+
+	// Proxy заменяет BrowserOptions.Proxy; в удаленном браузере задается отдельным browser context
+	Proxy *Proxy `json:"proxy,omitempty"`
+
+	// Session cookies и хранилища, восстанавливаемые до первой навигации
+	Session *Session `json:"session,omitempty"`
+
+	// Geolocation, Timezone (IANA, например "Europe/Berlin") и Locale (например "de-DE") эмулируются в каждой вкладке
+	Geolocation *Geolocation `json:"geolocation,omitempty"`
+	Timezone    string       `json:"timezone,omitempty"`
+	Locale      string       `json:"locale,omitempty"`
+
+	// Typing заменяет BrowserOptions.Typing: у каждой личности свой ритм печати
+	Typing *TypingOptions `json:"typing,omitempty"`
+}
+
+// Geolocation координаты для эмуляции геолокации
+type Geolocation struct {
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+	// Accuracy точность в метрах (0 - значение по умолчанию)
+	Accuracy float64 `json:"accuracy,omitempty"`
+}
+
+// ProfileStore хранилище профилей
+type ProfileStore interface {
+	// Load загружает профиль по ID
+	Load(id string) (*Profile, error)
+	// Save сохраняет профиль, заменяя профиль с тем же ID
+	Save(profile *Profile) error
+	// Delete удаляет профиль
+	Delete(id string) error
+	// List возвращает ID всех сохраненных профилей
+	List() ([]string, error)
+}
+
+// FileProfileStore хранит каждый профиль в отдельном JSON файле <dir>/<id>.json
+type FileProfileStore struct {
+	dir string
+}
+
+// NewFileProfileStore создает хранилище профилей в директории dir, создавая ее при необходимости
+func NewFileProfileStore(dir string) (*FileProfileStore, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("failed to create profile directory: %w", err)
+	}
+	return &FileProfileStore{dir: dir}, nil
+}
+
+// Load загружает профиль по ID
+// Если профиль не найден, ошибка удовлетворяет errors.Is(err, os.ErrNotExist)
+func (s *FileProfileStore) Load(id string) (*Profile, error) {
+	path, err := s.path(id)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read profile %s: %w", id, err)
+	}
+
+	var profile Profile
+	if err := json.Unmarshal(data, &profile); err != nil {
+		return nil, fmt.Errorf("failed to decode profile %s: %w", id, err)
+	}
+	return &profile, nil
+}
+
+// Save сохраняет профиль; файл заменяется атомарно
+func (s *FileProfileStore) Save(profile *Profile) error {
+	path, err := s.path(profile.ID)
+	if err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(profile, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode profile %s: %w", profile.ID, err)
+	}
+
+	tmp, err := os.CreateTemp(s.dir, profile.ID+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to save profile %s: %w", profile.ID, err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to save profile %s: %w", profile.ID, err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to save profile %s: %w", profile.ID, err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to save profile %s: %w", profile.ID, err)
+	}
+	return nil
+}
+
+// Delete удаляет профиль
+func (s *FileProfileStore) Delete(id string) error {
+	path, err := s.path(id)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil {
+		return fmt.Errorf("failed to delete profile %s: %w", id, err)
+	}
+	return nil
+}
+
+// List возвращает ID всех сохраненных профилей
+func (s *FileProfileStore) List() ([]string, error) {
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list profiles: %w", err)
+	}
+
+	var ids []string
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || filepath.Ext(name) != ".json" {
+			continue
+		}
+		ids = append(ids, strings.TrimSuffix(name, ".json"))
+	}
+	return ids, nil
+}
+
+// path возвращает путь к файлу профиля, проверяя, что ID не выходит за пределы директории
+func (s *FileProfileStore) path(id string) (string, error) {
+	if id == "" || id == "." || id == ".." || strings.ContainsAny(id, `/\`) {
+		return "", fmt.Errorf("invalid profile ID: %q", id)
+	}
+	return filepath.Join(s.dir, id+".json"), nil
+}
+
+// withProfile возвращает копию опций, в которой Fingerprint, Proxy и Typing заменены значениями профиля
+func withProfile(options *BrowserOptions, profile *Profile) *BrowserOptions {
+	merged := *options
+	merged.Profile = profile
+	if profile == nil {
+		return &merged
+	}
+	if profile.Fingerprint != nil {
+		merged.Fingerprint = profile.Fingerprint
+	}
+	if profile.Proxy != nil {
+		merged.Proxy = profile.Proxy
+	}
+	if profile.Typing != nil {
+		merged.Typing = profile.Typing
+	}
+	return &merged
+}
+
+// restoreProfileSession восстанавливает сессию профиля во вкладке, еще не открывавшей страниц
+func (b *Browser) restoreProfileSession(p *Page) error {
+	profile := b.options.Profile
+	if profile == nil || profile.Session == nil {
+		return nil
+	}
+	if err := p.RestoreSession(profile.Session); err != nil {
+		return fmt.Errorf("failed to restore session of profile %s: %w", profile.ID, err)
+	}
+	return nil
+}
+
+// disposeOwnedContext удаляет browser context вкладки, открытой через OpenTabWithProfile
+func (b *Browser) disposeOwnedContext() {
+	b.mu.Lock()
+	id := b.ownedContext
+	b.ownedContext = ""
+	b.mu.Unlock()
+	if id == "" {
+		return
+	}
+
+	// Ошибку игнорируем: контекст мог быть удален браузером при отключении
+	b.runBrowser(target.DisposeBrowserContext(id).Do)
+}
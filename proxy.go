@@ -0,0 +1,96 @@
+package osciris
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/chromedp/cdproto/fetch"
+)
+
+// Proxy настройки HTTP/SOCKS прокси
+type Proxy struct {
+	// Scheme схема прокси: "http", "https", "socks4", "socks5" (по умолчанию "http")
+	Scheme string
+
+	Host string
+	Port int
+
+	// Username и Password для авторизации на прокси (Chrome не поддерживает авторизацию для SOCKS)
+	Username string
+	Password string
+
+	// Bypass список хостов, которые открываются напрямую (например, "localhost", "*.internal")
+	Bypass []string
+}
+
+// Server возвращает адрес прокси в формате --proxy-server (scheme://host:port)
+func (p *Proxy) Server() string {
+	scheme := p.Scheme
+	if scheme == "" {
+		scheme = "http"
+	}
+	if p.Port == 0 {
+		return fmt.Sprintf("%s://%s", scheme, p.Host)
+	}
+	return fmt.Sprintf("%s://%s:%d", scheme, p.Host, p.Port)
+}
+
+// BypassList возвращает список исключений в формате --proxy-bypass-list
+func (p *Proxy) BypassList() string {
+	return strings.Join(p.Bypass, ";")
+}
+
+// hasAuth проверяет, требуется ли авторизация на прокси
+func (p *Proxy) hasAuth() bool {
+	return p.Username != "" || p.Password != ""
+}
+
+// SetProxyAuth отвечает на запросы авторизации прокси страницы указанными учетными данными
+// Запросы авторизации от сайтов (не от прокси) обрабатываются браузером как обычно
+func (p *Page) SetProxyAuth(username, password string) error {
+	router := p.fetchRouter()
+	if err := router.setAuth(&fetch.AuthChallengeResponse{
+		Response: fetch.AuthChallengeResponseResponseProvideCredentials,
+		Username: username,
+		Password: password,
+	}); err != nil {
+		return fmt.Errorf("failed to enable proxy auth: %w", err)
+	}
+	return nil
+}
+
+// applyProxyAuth применяет к странице вкладки авторизацию ее прокси (или BrowserOptions.Proxy)
+func (b *Browser) applyProxyAuth(p *Page) error {
+	proxy := p.proxy
+	if proxy == nil {
+		proxy = b.options.Proxy
+	}
+	if proxy == nil || !proxy.hasAuth() {
+		return nil
+	}
+	return p.SetProxyAuth(proxy.Username, proxy.Password)
+}
+
+// setAuth задает учетные данные прокси и включает обработку authRequired
+func (fr *fetchRouter) setAuth(auth *fetch.AuthChallengeResponse) error {
+	fr.mu.Lock()
+	fr.auth = auth
+	fr.mu.Unlock()
+
+	return fr.sync()
+}
+
+// handleAuth отвечает на запрос авторизации
+func (fr *fetchRouter) handleAuth(ev *fetch.EventAuthRequired) {
+	fr.mu.Lock()
+	auth := fr.auth
+	fr.mu.Unlock()
+
+	response := &fetch.AuthChallengeResponse{Response: fetch.AuthChallengeResponseResponseDefault}
+	if auth != nil && ev.AuthChallenge != nil && ev.AuthChallenge.Source == fetch.AuthChallengeSourceProxy {
+		response = auth
+	}
+
+	// Ошибку игнорируем: запрос мог быть отменен страницей
+	fr.page.run(fetch.ContinueWithAuth(ev.RequestID, response))
+}
@@ -0,0 +1,256 @@
+package osciris
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/chromedp/cdproto/network"
+	"github.com/chromedp/chromedp"
+)
+
+// NetworkMatcher условие отбора запросов и ответов страницы
+// Пустые поля не участвуют в проверке
+type NetworkMatcher struct {
+	// URL шаблон URL ('*' - любое количество символов, '?' - ровно один символ)
+	URL string
+
+	// Method HTTP метод (без учета регистра)
+	Method string
+
+	// Status код ответа (только для WaitForResponse)
+	Status int64
+
+	// ResourceType тип ресурса (network.ResourceTypeXHR, network.ResourceTypeFetch и т.д.)
+	ResourceType network.ResourceType
+
+	// Func дополнительная проверка; для WaitForRequest resp равен nil
+	Func func(req *Request, resp *Response) bool
+}
+
+// Request запрос страницы
+type Request struct {
+	ID           network.RequestID
+	URL          string
+	Method       string
+	Headers      map[string]string
+	PostData     []byte
+	ResourceType network.ResourceType
+}
+
+// Response ответ на запрос страницы
+type Response struct {
+	Request      *Request
+	URL          string
+	Status       int64
+	StatusText   string
+	Headers      map[string]string
+	MimeType     string
+	ResourceType network.ResourceType
+
+	page *Page
+	// finished закрывается после loadingFinished/loadingFailed или таймаута: только тогда тело доступно
+	finished   chan struct{}
+	finishOnce sync.Once
+	failed     string
+
+	bodyOnce sync.Once
+	body     []byte
+	bodyErr  error
+}
+
+// Body возвращает тело ответа, загружая его при первом обращении (Network.getResponseBody)
+func (r *Response) Body() ([]byte, error) {
+	r.bodyOnce.Do(func() {
+		select {
+		case <-r.finished:
+		case <-time.After(r.page.browser.options.Timeout):
+			r.bodyErr = fmt.Errorf("timeout waiting for response body: %s", r.URL)
+			return
+		}
+		if r.failed != "" {
+			r.bodyErr = fmt.Errorf("response loading failed: %s", r.failed)
+			return
+		}
+
+		r.bodyErr = r.page.run(chromedp.ActionFunc(func(ctx context.Context) error {
+			var err error
+			r.body, err = network.GetResponseBody(r.Request.ID).Do(ctx)
+			return err
+		}))
+	})
+	return r.body, r.bodyErr
+}
+
+// finish отмечает завершение загрузки ответа (failed - причина ошибки)
+func (r *Response) finish(failed string) {
+	r.finishOnce.Do(func() {
+		r.failed = failed
+		close(r.finished)
+	})
+}
+
+// WaitForResponse ждет ответ, удовлетворяющий условию matcher
+// Ответ возвращается сразу после получения заголовков; тело доступно через Response.Body
+func (p *Page) WaitForResponse(matcher NetworkMatcher, timeout time.Duration) (*Response, error) {
+	urlRe, err := compileURLPattern(matcher.URL)
+	if err != nil {
+		return nil, err
+	}
+
+	// Подключаемся к вкладке, чтобы слушатель получал события Network domain
+	if err := p.run(); err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(p.ctx, timeout)
+	defer cancel()
+
+	// Слушатель живет до завершения загрузки найденного ответа, но не дольше Timeout, чтобы Body знал, когда тело доступно
+	lctx, lcancel := context.WithCancel(p.ctx)
+
+	found := make(chan *Response, 1)
+	requests := make(map[network.RequestID]*Request)
+	var matched *Response
+
+	chromedp.ListenTarget(lctx, func(ev any) {
+		switch ev := ev.(type) {
+		case *network.EventRequestWillBeSent:
+			if matched == nil {
+				requests[ev.RequestID] = newRequest(ev.RequestID, ev.Request, ev.Type)
+			}
+		case *network.EventResponseReceived:
+			if matched != nil {
+				return
+			}
+			req, ok := requests[ev.RequestID]
+			delete(requests, ev.RequestID)
+			if !ok {
+				// Запрос отправлен до подписки: известен только URL ответа
+				req = &Request{ID: ev.RequestID, URL: ev.Response.URL, ResourceType: ev.Type}
+			}
+			resp := &Response{
+				Request:      req,
+				URL:          ev.Response.URL,
+				Status:       ev.Response.Status,
+				StatusText:   ev.Response.StatusText,
+				Headers:      headersMap(ev.Response.Headers),
+				MimeType:     ev.Response.MimeType,
+				ResourceType: ev.Type,
+				page:         p,
+				finished:     make(chan struct{}),
+			}
+			if !matcher.match(urlRe, req, resp) {
+				return
+			}
+			matched = resp
+			found <- resp
+			go func() {
+				timer := time.NewTimer(p.browser.options.Timeout)
+				defer timer.Stop()
+				select {
+				case <-resp.finished:
+				case <-timer.C:
+					// Потоковые ответы (SSE, long-poll) не завершаются, а слушатель не должен жить до закрытия вкладки
+					resp.finish("response is still loading after timeout")
+				case <-lctx.Done():
+				}
+				lcancel()
+			}()
+		case *network.EventLoadingFinished:
+			if matched != nil && ev.RequestID == matched.Request.ID {
+				matched.finish("")
+			}
+		case *network.EventLoadingFailed:
+			if matched != nil && ev.RequestID == matched.Request.ID {
+				matched.finish(ev.ErrorText)
+			}
+		}
+	})
+
+	select {
+	case resp := <-found:
+		return resp, nil
+	case <-ctx.Done():
+		lcancel()
+		return nil, fmt.Errorf("timeout waiting for response")
+	}
+}
+
+// WaitForRequest ждет отправку запроса, удовлетворяющего условию matcher (поле Status не учитывается)
+func (p *Page) WaitForRequest(matcher NetworkMatcher, timeout time.Duration) (*Request, error) {
+	urlRe, err := compileURLPattern(matcher.URL)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := p.run(); err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(p.ctx, timeout)
+	defer cancel()
+
+	found := make(chan *Request, 1)
+
+	chromedp.ListenTarget(ctx, func(ev any) {
+		e, ok := ev.(*network.EventRequestWillBeSent)
+		if !ok {
+			return
+		}
+		req := newRequest(e.RequestID, e.Request, e.Type)
+		if !matcher.match(urlRe, req, nil) {
+			return
+		}
+		select {
+		case found <- req:
+		default:
+		}
+	})
+
+	select {
+	case req := <-found:
+		return req, nil
+	case <-ctx.Done():
+		return nil, fmt.Errorf("timeout waiting for request")
+	}
+}
+
+// match проверяет запрос и ответ (resp может быть nil) на соответствие условию
+func (m *NetworkMatcher) match(urlRe *regexp.Regexp, req *Request, resp *Response) bool {
+	url := req.URL
+	if resp != nil {
+		url = resp.URL
+	}
+	if !urlRe.MatchString(url) {
+		return false
+	}
+	if m.Method != "" && !strings.EqualFold(m.Method, req.Method) {
+		return false
+	}
+	if m.ResourceType != "" && m.ResourceType != req.ResourceType {
+		return false
+	}
+	if resp != nil && m.Status != 0 && m.Status != resp.Status {
+		return false
+	}
+	if m.Func != nil && !m.Func(req, resp) {
+		return false
+	}
+	return true
+}
+
+// newRequest преобразует запрос Network domain в Request
+func newRequest(id network.RequestID, req *network.Request, resourceType network.ResourceType) *Request {
+	return &Request{
+		ID:           id,
+		URL:          req.URL,
+		Method:       req.Method,
+		Headers:      headersMap(req.Headers),
+		PostData:     postData(req),
+		ResourceType: resourceType,
+	}
+}
@@ -0,0 +1,269 @@
+package osciris
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/chromedp/cdproto/domstorage"
+	"github.com/chromedp/cdproto/network"
+	"github.com/chromedp/cdproto/page"
+	cdruntime "github.com/chromedp/cdproto/runtime"
+	"github.com/chromedp/chromedp"
+)
+
+// Session снимок состояния вкладки: cookies и хранилища origin'ов
+// Сериализуется в JSON через WriteSession/ReadSession
+type Session struct {
+	Cookies []*network.Cookie `json:"cookies"`
+	Origins []*OriginStorage  `json:"origins"`
+}
+
+// OriginStorage хранилища одного origin
+type OriginStorage struct {
+	Origin         string            `json:"origin"`
+	LocalStorage   map[string]string `json:"localStorage,omitempty"`
+	SessionStorage map[string]string `json:"sessionStorage,omitempty"`
+	// IndexedDB сохраняется только для origin основного фрейма
+	IndexedDB []*IndexedDBDatabase `json:"indexedDB,omitempty"`
+}
+
+// IndexedDBDatabase база IndexedDB
+type IndexedDBDatabase struct {
+	Name    string            `json:"name"`
+	Version int64             `json:"version"`
+	Stores  []*IndexedDBStore `json:"stores"`
+}
+
+// IndexedDBStore object store базы IndexedDB
+// Ключи и значения хранятся в JSON, поэтому Date, Blob и другие не-JSON типы не сохраняются
+type IndexedDBStore struct {
+	Name          string             `json:"name"`
+	KeyPath       json.RawMessage    `json:"keyPath"`
+	AutoIncrement bool               `json:"autoIncrement"`
+	Indexes       []*IndexedDBIndex  `json:"indexes"`
+	Records       []*IndexedDBRecord `json:"records"`
+}
+
+// IndexedDBIndex индекс object store
+type IndexedDBIndex struct {
+	Name       string          `json:"name"`
+	KeyPath    json.RawMessage `json:"keyPath"`
+	Unique     bool            `json:"unique"`
+	MultiEntry bool            `json:"multiEntry"`
+}
+
+// IndexedDBRecord запись object store
+type IndexedDBRecord struct {
+	Key   json.RawMessage `json:"key"`
+	Value json.RawMessage `json:"value"`
+}
+
+// indexedDBDumpScript выгружает все базы IndexedDB текущего origin
+const indexedDBDumpScript = `(async () => {
+	const request = (r) => new Promise((resolve, reject) => {
+		r.onsuccess = () => resolve(r.result);
+		r.onerror = () => reject(r.error);
+	});
+	const dump = [];
+	if (!indexedDB.databases) return dump;
+	for (const info of await indexedDB.databases()) {
+		const db = await request(indexedDB.open(info.name));
+		const stores = [];
+		for (const name of db.objectStoreNames) {
+			const store = db.transaction(name, 'readonly').objectStore(name);
+			const keys = await request(store.getAllKeys());
+			const values = await request(store.getAll());
+			stores.push({
+				name: name,
+				keyPath: store.keyPath,
+				autoIncrement: store.autoIncrement,
+				indexes: Array.from(store.indexNames).map((i) => {
+					const index = store.index(i);
+					return {name: index.name, keyPath: index.keyPath, unique: index.unique, multiEntry: index.multiEntry};
+				}),
+				records: keys.map((key, i) => ({key: key, value: values[i]})),
+			});
+		}
+		dump.push({name: db.name, version: db.version, stores: stores});
+		db.close();
+	}
+	return dump;
+})()`
+
+// storageRestoreScript восстанавливает хранилища origin; %s - OriginStorage в JSON
+const storageRestoreScript = `(async (data) => {
+	for (const [key, value] of Object.entries(data.localStorage || {})) localStorage.setItem(key, value);
+	for (const [key, value] of Object.entries(data.sessionStorage || {})) sessionStorage.setItem(key, value);
+	for (const database of data.indexedDB || []) {
+		await new Promise((resolve, reject) => {
+			const r = indexedDB.open(database.name, database.version);
+			r.onupgradeneeded = () => {
+				const db = r.result;
+				for (const s of database.stores) {
+					if (db.objectStoreNames.contains(s.name)) db.deleteObjectStore(s.name);
+					const store = db.createObjectStore(s.name, {keyPath: s.keyPath, autoIncrement: s.autoIncrement});
+					for (const i of s.indexes) store.createIndex(i.name, i.keyPath, {unique: i.unique, multiEntry: i.multiEntry});
+					for (const record of s.records) {
+						if (s.keyPath === null) store.put(record.value, record.key);
+						else store.put(record.value);
+					}
+				}
+			};
+			r.onsuccess = () => { r.result.close(); resolve(); };
+			r.onerror = () => reject(r.error);
+		});
+	}
+	return true;
+})(%s)`
+
+// SaveSession сохраняет cookies browser context страницы, localStorage и sessionStorage всех origin'ов
+// фреймов страницы и IndexedDB origin основного фрейма
+func (p *Page) SaveSession() (*Session, error) {
+	cookies, err := p.browser.contextCookies(p.browserContextID())
+	if err != nil {
+		return nil, err
+	}
+	session := &Session{Cookies: cookies}
+
+	var tree *page.FrameTree
+	err = p.run(chromedp.ActionFunc(func(ctx context.Context) error {
+		var err error
+		tree, err = page.GetFrameTree().Do(ctx)
+		return err
+	}))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get frame tree: %w", err)
+	}
+
+	mainOrigin := tree.Frame.SecurityOrigin
+	for _, origin := range frameOrigins(tree, nil) {
+		storage := &OriginStorage{Origin: origin}
+
+		err := p.run(domstorage.Enable(), chromedp.ActionFunc(func(ctx context.Context) error {
+			var err error
+			if storage.LocalStorage, err = domStorageItems(ctx, origin, true); err != nil {
+				return err
+			}
+			storage.SessionStorage, err = domStorageItems(ctx, origin, false)
+			return err
+		}))
+		if err != nil {
+			return nil, fmt.Errorf("failed to get storage of %s: %w", origin, err)
+		}
+
+		if origin == mainOrigin {
+			if err := p.run(chromedp.Evaluate(indexedDBDumpScript, &storage.IndexedDB, awaitPromise)); err != nil {
+				return nil, fmt.Errorf("failed to dump IndexedDB of %s: %w", origin, err)
+			}
+		}
+
+		session.Origins = append(session.Origins, storage)
+	}
+
+	return session, nil
+}
+
+// RestoreSession восстанавливает сохраненную сессию в новой вкладке до первой навигации
+// Для каждого origin вкладка открывает пустую страницу этого origin (ответ подставляется через Fetch, в сеть запрос не уходит),
+// заполняет хранилища и возвращается на about:blank
+func (p *Page) RestoreSession(session *Session) error {
+	if err := p.browser.setContextCookies(p.browserContextID(), session.Cookies); err != nil {
+		return err
+	}
+
+	for _, storage := range session.Origins {
+		if err := p.restoreOrigin(storage); err != nil {
+			return fmt.Errorf("failed to restore storage of %s: %w", storage.Origin, err)
+		}
+	}
+
+	return p.Navigate("about:blank")
+}
+
+// restoreOrigin открывает пустую страницу origin и заполняет ее хранилища
+func (p *Page) restoreOrigin(storage *OriginStorage) error {
+	data, err := json.Marshal(storage)
+	if err != nil {
+		return err
+	}
+
+	origin := strings.TrimSuffix(storage.Origin, "/")
+	stop, err := p.Intercept([]RequestPattern{{URL: origin + "/*"}}, func(req *InterceptedRequest) {
+		req.Fulfill(200, map[string]string{"Content-Type": "text/html"}, []byte("<html><head></head><body></body></html>"))
+	})
+	if err != nil {
+		return err
+	}
+	defer stop()
+
+	if err := p.Navigate(origin + "/"); err != nil {
+		return err
+	}
+
+	var ok bool
+	return p.run(chromedp.Evaluate(fmt.Sprintf(storageRestoreScript, data), &ok, awaitPromise))
+}
+
+// WriteSession записывает сессию в JSON
+func (s *Session) WriteSession(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(s)
+}
+
+// ReadSession читает сессию, записанную через WriteSession
+func ReadSession(r io.Reader) (*Session, error) {
+	var session Session
+	if err := json.NewDecoder(r).Decode(&session); err != nil {
+		return nil, fmt.Errorf("failed to decode session: %w", err)
+	}
+	return &session, nil
+}
+
+// frameOrigins собирает уникальные origin'ы фреймов (кроме opaque origin'ов about:blank, data: и т.д.)
+func frameOrigins(tree *page.FrameTree, origins []string) []string {
+	origin := tree.Frame.SecurityOrigin
+	if strings.HasPrefix(origin, "http://") || strings.HasPrefix(origin, "https://") {
+		seen := false
+		for _, o := range origins {
+			if o == origin {
+				seen = true
+				break
+			}
+		}
+		if !seen {
+			origins = append(origins, origin)
+		}
+	}
+	for _, child := range tree.ChildFrames {
+		origins = frameOrigins(child, origins)
+	}
+	return origins
+}
+
+// domStorageItems возвращает содержимое localStorage или sessionStorage origin
+func domStorageItems(ctx context.Context, origin string, local bool) (map[string]string, error) {
+	items, err := domstorage.GetDOMStorageItems(&domstorage.StorageID{
+		SecurityOrigin: origin,
+		IsLocalStorage: local,
+	}).Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(map[string]string, len(items))
+	for _, item := range items {
+		if len(item) == 2 {
+			result[item[0]] = item[1]
+		}
+	}
+	return result, nil
+}
+
+// awaitPromise опция chromedp.Evaluate, ожидающая завершения Promise
+func awaitPromise(p *cdruntime.EvaluateParams) *cdruntime.EvaluateParams {
+	return p.WithAwaitPromise(true)
+}
@@ -0,0 +1,262 @@
+package osciris
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/chromedp/cdproto/cdp"
+	"github.com/chromedp/cdproto/target"
+	"github.com/chromedp/chromedp"
+)
+
+// NewTab открывает новую вкладку в браузере и возвращает привязанную к ней страницу
+// Вкладка создается в том же браузере, что и Browser, и закрывается через Page.Close
+func (b *Browser) NewTab(url string) (*Page, error) {
+	return b.newTab(url, &Page{browser: b})
+}
+
+// NewIsolatedTab открывает вкладку в отдельном browser context и возвращает привязанную к ней страницу
+// Cookies, storage и кэш вкладки изолированы от остальных вкладок; если proxy не nil, вкладка работает через него
+// Page.Close закрывает вкладку и удаляет ее browser context
+func (b *Browser) NewIsolatedTab(url string, proxy *Proxy) (*Page, error) {
+	contextOption := func(params *target.CreateBrowserContextParams) *target.CreateBrowserContextParams {
+		if proxy == nil {
+			return params
+		}
+		params = params.WithProxyServer(proxy.Server())
+		if len(proxy.Bypass) > 0 {
+			params = params.WithProxyBypassList(proxy.BypassList())
+		}
+		return params
+	}
+
+	return b.newTab(url, &Page{browser: b, proxy: proxy}, chromedp.WithNewBrowserContext(contextOption))
+}
+
+// newTab создает вкладку с опциями chromedp контекста, привязывает к ней страницу p и переходит на url
+func (b *Browser) newTab(url string, p *Page, opts ...chromedp.ContextOption) (*Page, error) {
+	parent, err := b.browserSession()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create new tab: %w", err)
+	}
+
+	// Вкладка использует общее с CDP-сессией браузера соединение
+	tabCtx, tabCancel := chromedp.NewContext(parent, opts...)
+
+	// Первый Run создает target; выполняем его без таймаута, так как отмена контекста закроет вкладку
+	if err := chromedp.Run(tabCtx); err != nil {
+		tabCancel()
+		return nil, fmt.Errorf("failed to create new tab: %w", err)
+	}
+
+	p.ctx, p.cancel = tabCtx, tabCancel
+	if err := b.setupTab(p); err != nil {
+		return nil, err
+	}
+
+	// Если URL был указан, переходим на него
+	if url != "" {
+		if err := p.Navigate(url); err != nil {
+			p.Close()
+			return nil, fmt.Errorf("failed to navigate: %w", err)
+		}
+	}
+
+	return p, nil
+}
+
+// AttachTab подключается к существующей вкладке по ID и возвращает привязанную к ней страницу
+func (b *Browser) AttachTab(targetID target.ID) (*Page, error) {
+	parent, err := b.browserSession()
+	if err != nil {
+		return nil, fmt.Errorf("failed to attach to tab: %w", err)
+	}
+
+	tabCtx, tabCancel := chromedp.NewContext(parent, chromedp.WithTargetID(targetID))
+
+	if err := chromedp.Run(tabCtx); err != nil {
+		tabCancel()
+		return nil, fmt.Errorf("failed to attach to tab %s: %w", targetID, err)
+	}
+
+	p := &Page{browser: b, ctx: tabCtx, cancel: tabCancel}
+	if err := b.setupTab(p); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// Pages возвращает страницы, открытые через NewTab/AttachTab и еще не закрытые
+func (b *Browser) Pages() []*Page {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	pages := make([]*Page, len(b.pages))
+	copy(pages, b.pages)
+	return pages
+}
+
+// TargetID возвращает ID вкладки, к которой привязана страница
+// Для страницы, к которой еще не выполнялось ни одного действия, возвращает пустую строку
+func (p *Page) TargetID() target.ID {
+	if c := chromedp.FromContext(p.ctx); c != nil && c.Target != nil {
+		return c.Target.TargetID
+	}
+	return ""
+}
+
+// Close закрывает вкладку страницы
+// Страницу, полученную через Browser.NewPage, закрывает только Browser.Close
+func (p *Page) Close() error {
+	if p.cancel == nil {
+		return fmt.Errorf("page is bound to the browser context, use Browser.Close")
+	}
+
+	p.cancel()
+	if p.context != nil {
+		p.context.removePage(p)
+	} else {
+		p.browser.removePage(p)
+	}
+	return nil
+}
+
+// browserSession возвращает постоянную CDP-сессию уровня браузера
+// Сессия привязана к browser target, а не к странице, поэтому не создает лишних вкладок
+func (b *Browser) browserSession() (context.Context, error) {
+	b.sessionMu.Lock()
+	defer b.sessionMu.Unlock()
+
+	if b.sessionCtx != nil {
+		return b.sessionCtx, nil
+	}
+
+	c := chromedp.FromContext(b.ctx)
+	if c == nil {
+		return nil, chromedp.ErrInvalidContext
+	}
+
+	// Запускаем локальный Chrome вместе с его первой вкладкой
+	if c.Browser == nil && !b.isRemote {
+		if err := chromedp.Run(b.ctx); err != nil {
+			return nil, fmt.Errorf("failed to start browser: %w", err)
+		}
+	}
+
+	// Контекст без Run наследует соединение с браузером, но не создает target
+	sessionCtx, cancel := chromedp.NewContext(b.ctx)
+	if s := chromedp.FromContext(sessionCtx); s.Browser == nil {
+		// Удаленный браузер еще не подключен: отдельное соединение сессии без создания вкладки
+		// Контекст сессии пока не виден другим горутинам, а соединение закрывается вместе с ним
+		browser, err := s.Allocator.Allocate(sessionCtx)
+		if err != nil {
+			cancel()
+			return nil, fmt.Errorf("failed to connect to browser: %w", err)
+		}
+		s.Browser = browser
+	}
+
+	b.sessionCtx, b.sessionCancel = sessionCtx, cancel
+	return b.sessionCtx, nil
+}
+
+// closeSession закрывает CDP-сессию уровня браузера и собственное соединение дочерних целей
+func (b *Browser) closeSession() {
+	b.sessionMu.Lock()
+	defer b.sessionMu.Unlock()
+
+	if b.sessionCancel != nil {
+		b.sessionCancel()
+		b.sessionCtx, b.sessionCancel = nil, nil
+	}
+	if b.devtools != nil {
+		b.devtools.Close()
+		b.devtools = nil
+	}
+}
+
+// runBrowser выполняет CDP команды уровня браузера (target.*) через постоянную сессию
+func (b *Browser) runBrowser(fn func(ctx context.Context) error) error {
+	sessionCtx, err := b.browserSession()
+	if err != nil {
+		return err
+	}
+
+	timeoutCtx, cancel := context.WithTimeout(sessionCtx, b.options.Timeout)
+	defer cancel()
+
+	return fn(cdp.WithExecutor(timeoutCtx, chromedp.FromContext(sessionCtx).Browser))
+}
+
+// setupTab регистрирует страницу вкладки и применяет к ней fingerprint и настройки из BrowserOptions
+// Для страницы BrowserContext используются его fingerprint и прокси
+func (b *Browser) setupTab(p *Page) error {
+	_, injector := b.pageFingerprint(p)
+
+	// Применяем fingerprint
+	if injector != nil {
+		fpCtx, fpCancel := context.WithTimeout(p.ctx, b.options.Timeout)
+		defer fpCancel()
+
+		if err := chromedp.Run(fpCtx, injector.ApplyAll(fpCtx)); err != nil {
+			p.cancel()
+			return fmt.Errorf("failed to apply fingerprint: %w", err)
+		}
+	}
+
+	// Применяем настройки вкладки (блокировка, авторизация прокси, client hints, эмуляция, дочерние цели)
+	if err := b.applyPageOptions(p); err != nil {
+		p.cancel()
+		return err
+	}
+
+	if p.context != nil {
+		if err := p.context.addPage(p); err != nil {
+			p.cancel()
+			return err
+		}
+		return nil
+	}
+
+	b.mu.Lock()
+	b.pages = append(b.pages, p)
+	b.mu.Unlock()
+
+	return nil
+}
+
+// applyPageOptions применяет к странице вкладки настройки BrowserOptions, которые действуют на уровне вкладки
+func (b *Browser) applyPageOptions(p *Page) error {
+	if err := b.applyBlockPolicy(p); err != nil {
+		return err
+	}
+	if err := b.applyProxyAuth(p); err != nil {
+		return err
+	}
+	if err := b.applyUserAgent(p); err != nil {
+		return err
+	}
+	if err := b.applyEmulation(p); err != nil {
+		return err
+	}
+	if err := b.applyChildTargets(p); err != nil {
+		return err
+	}
+	if b.options.HumanMouse != nil {
+		p.EnableHumanMouse(b.humanMouseOptions())
+	}
+	return nil
+}
+
+// removePage удаляет страницу из списка открытых вкладок
+func (b *Browser) removePage(p *Page) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for i, page := range b.pages {
+		if page == p {
+			b.pages = append(b.pages[:i], b.pages[i+1:]...)
+			return
+		}
+	}
+}
@@ -0,0 +1,125 @@
+package osciris
+
+import (
+	"context"
+	"math"
+	"time"
+
+	"github.com/chromedp/cdproto/input"
+	"github.com/chromedp/chromedp"
+)
+
+// Point координаты в CSS пикселях относительно viewport
+type Point struct {
+	X float64
+	Y float64
+}
+
+// touchMoveInterval интервал между touchMove событиями (частота кадров 60 Гц)
+const touchMoveInterval = 16 * time.Millisecond
+
+// defaultLongPress длительность долгого нажатия по умолчанию
+const defaultLongPress = 800 * time.Millisecond
+
+// releaseTimeout таймаут отпускания после отмены действия
+const releaseTimeout = 5 * time.Second
+
+// Tap касается центра элемента (touchStart/touchEnd)
+// Работает и без EmulateDevice, но обработчики touch событий сайта обычно ожидают включенной эмуляции устройства
+func (p *Page) Tap(selector string) error {
+	return p.run(tapSelector(selector))
+}
+
+// TapXY касается точки
+func (p *Page) TapXY(x, y float64) error {
+	return p.run(chromedp.ActionFunc(func(ctx context.Context) error {
+		return dispatchTap(ctx, x, y, tapDuration())
+	}))
+}
+
+// LongPress удерживает палец на центре элемента (duration 0 - 800ms)
+func (p *Page) LongPress(selector string, duration time.Duration) error {
+	if duration <= 0 {
+		duration = defaultLongPress
+	}
+	return p.run(chromedp.ActionFunc(func(ctx context.Context) error {
+		x, y, err := nodeCenter(ctx, selector)
+		if err != nil {
+			return err
+		}
+		return dispatchTap(ctx, x, y, duration)
+	}))
+}
+
+// Swipe проводит пальцем от from до to за duration
+// Движение ускоряется в начале и замедляется в конце, как у настоящего свайпа
+func (p *Page) Swipe(from, to Point, duration time.Duration) error {
+	steps := int(duration / touchMoveInterval)
+	if steps < 2 {
+		steps = 2
+	}
+	return p.run(chromedp.ActionFunc(func(ctx context.Context) (err error) {
+		if err := input.DispatchTouchEvent(input.TouchStart, []*input.TouchPoint{{X: from.X, Y: from.Y}}).Do(ctx); err != nil {
+			return err
+		}
+		defer func() {
+			if endErr := releaseTouch(ctx); err == nil {
+				err = endErr
+			}
+		}()
+		for i := 1; i <= steps; i++ {
+			if err := sleep(ctx, touchMoveInterval); err != nil {
+				return err
+			}
+			t := easeInOut(float64(i) / float64(steps))
+			point := &input.TouchPoint{
+				X: from.X + (to.X-from.X)*t,
+				Y: from.Y + (to.Y-from.Y)*t,
+			}
+			if err := input.DispatchTouchEvent(input.TouchMove, []*input.TouchPoint{point}).Do(ctx); err != nil {
+				return err
+			}
+		}
+		return nil
+	}))
+}
+
+// Pinch выполняет жест масштабирования двумя пальцами вокруг center
+// scale > 1 - увеличение, scale < 1 - уменьшение
+func (p *Page) Pinch(center Point, scale float64) error {
+	return p.run(chromedp.ActionFunc(func(ctx context.Context) error {
+		return input.SynthesizePinchGesture(center.X, center.Y, scale).
+			WithGestureSourceType(input.GestureTouch).
+			Do(ctx)
+	}))
+}
+
+// TouchScroll прокручивает страницу или прокручиваемый элемент под точкой at жестом пальца
+// Положительный distance прокручивает вниз/вправо; в отличие от MouseWheel работает при эмуляции мобильного устройства
+func (p *Page) TouchScroll(at Point, distanceX, distanceY float64) error {
+	return p.run(chromedp.ActionFunc(func(ctx context.Context) error {
+		// В synthesizeScrollGesture отрицательное расстояние прокручивает вниз (палец движется вверх)
+		return input.SynthesizeScrollGesture(at.X, at.Y).
+			WithXDistance(-distanceX).
+			WithYDistance(-distanceY).
+			WithGestureSourceType(input.GestureTouch).
+			Do(ctx)
+	}))
+}
+
+// sleep ждет d или отмены ctx
+func sleep(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
+
+// easeInOut сглаживает прогресс движения t в диапазоне [0, 1]
+func easeInOut(t float64) float64 {
+	return (1 - math.Cos(math.Pi*t)) / 2
+}
@@ -0,0 +1,515 @@
+package osciris
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"math/rand"
+	"runtime"
+	"sync"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/chromedp/cdproto/input"
+	"github.com/chromedp/chromedp"
+	"github.com/chromedp/chromedp/kb"
+)
+
+// KeyboardLayout раскладка клавиатуры, по которой рассчитываются задержки между клавишами и выбираются опечатки
+type KeyboardLayout string
+
+// Раскладки клавиатуры
+const (
+	LayoutQWERTY KeyboardLayout = "qwerty"
+	LayoutQWERTZ KeyboardLayout = "qwertz"
+	LayoutAZERTY KeyboardLayout = "azerty"
+	LayoutJCUKEN KeyboardLayout = "jcuken"
+)
+
+// TypingOptions настройки человекоподобного ввода текста (SendKeysChar)
+type TypingOptions struct {
+	// Seed инициализирует генератор случайных чисел; одинаковый seed дает одинаковые задержки и опечатки (0 - случайный)
+	Seed int64 `json:"seed,omitempty"`
+	// WPM средняя скорость печати в словах (5 символов) в минуту (0 - 60)
+	WPM float64 `json:"wpm,omitempty"`
+	// TypoRate вероятность опечатки в букве, исправляемой через Backspace (0 - 0.02, отрицательное значение отключает опечатки)
+	TypoRate float64 `json:"typoRate,omitempty"`
+	// Layout раскладка клавиатуры: задает code клавиш, ритм печати и соседние клавиши для опечаток ("" - LayoutQWERTY)
+	Layout KeyboardLayout `json:"layout,omitempty"`
+}
+
+// keyboardRows ряды клавиш раскладки сверху вниз
+type keyboardRows struct {
+	// rows символы ряда без Shift и с Shift
+	rows [4][2]string
+	// codes физические клавиши ряда (KeyboardEvent.code)
+	codes *[4][]string
+	// offsets сдвиг первой клавиши ряда в ширинах клавиши
+	offsets [4]float64
+	// home номер клавиши ряда, которую нажимает левый мизинец в слепой печати
+	home [4]int
+}
+
+var (
+	ansiOffsets = [4]float64{0, 1.5, 1.75, 2.25}
+	// В ISO-клавиатуре слева от нижнего ряда есть дополнительная клавиша
+	isoOffsets = [4]float64{0, 1.5, 1.75, 1.25}
+)
+
+var (
+	ansiCodes = [4][]string{
+		{"Backquote", "Digit1", "Digit2", "Digit3", "Digit4", "Digit5", "Digit6", "Digit7", "Digit8", "Digit9", "Digit0", "Minus", "Equal"},
+		{"KeyQ", "KeyW", "KeyE", "KeyR", "KeyT", "KeyY", "KeyU", "KeyI", "KeyO", "KeyP", "BracketLeft", "BracketRight", "Backslash"},
+		{"KeyA", "KeyS", "KeyD", "KeyF", "KeyG", "KeyH", "KeyJ", "KeyK", "KeyL", "Semicolon", "Quote"},
+		{"KeyZ", "KeyX", "KeyC", "KeyV", "KeyB", "KeyN", "KeyM", "Comma", "Period", "Slash"},
+	}
+	// В ISO-клавиатуре Backslash находится в среднем ряду, а IntlBackslash слева от Z
+	isoCodes = [4][]string{
+		ansiCodes[0],
+		ansiCodes[1][:12],
+		append(ansiCodes[2][:11:11], "Backslash"),
+		append([]string{"IntlBackslash"}, ansiCodes[3]...),
+	}
+)
+
+var keyboardLayouts = map[KeyboardLayout]keyboardRows{
+	LayoutQWERTY: {
+		rows: [4][2]string{
+			{"`1234567890-=", "~!@#$%^&*()_+"},
+			{`qwertyuiop[]\`, "QWERTYUIOP{}|"},
+			{"asdfghjkl;'", `ASDFGHJKL:"`},
+			{"zxcvbnm,./", "ZXCVBNM<>?"},
+		},
+		codes:   &ansiCodes,
+		offsets: ansiOffsets,
+		home:    [4]int{1, 0, 0, 0},
+	},
+	LayoutQWERTZ: {
+		rows: [4][2]string{
+			{"^1234567890ß´", "°!\"§$%&/()=?`"},
+			{"qwertzuiopü+", "QWERTZUIOPÜ*"},
+			{"asdfghjklöä#", "ASDFGHJKLÖÄ'"},
+			{"<yxcvbnm,.-", ">YXCVBNM;:_"},
+		},
+		codes:   &isoCodes,
+		offsets: isoOffsets,
+		home:    [4]int{1, 0, 0, 1},
+	},
+	LayoutAZERTY: {
+		rows: [4][2]string{
+			{"²&é\"'(-è_çà)=", "²1234567890°+"},
+			{"azertyuiop^$", "AZERTYUIOP¨£"},
+			{"qsdfghjklmù*", "QSDFGHJKLM%µ"},
+			{"<wxcvbn,;:!", ">WXCVBN?./§"},
+		},
+		codes:   &isoCodes,
+		offsets: isoOffsets,
+		home:    [4]int{1, 0, 0, 1},
+	},
+	LayoutJCUKEN: {
+		rows: [4][2]string{
+			{"ё1234567890-=", "Ё!\"№;%:?*()_+"},
+			{`йцукенгшщзхъ\`, "ЙЦУКЕНГШЩЗХЪ/"},
+			{"фывапролджэ", "ФЫВАПРОЛДЖЭ"},
+			{"ячсмитьбю.", "ЯЧСМИТЬБЮ,"},
+		},
+		codes:   &ansiCodes,
+		offsets: ansiOffsets,
+		home:    [4]int{1, 0, 0, 0},
+	},
+}
+
+// keyPosition положение символа на клавиатуре
+type keyPosition struct {
+	char  rune
+	row   int
+	x     float64
+	shift bool
+	// code физическая клавиша, base символ этой клавиши без Shift
+	code string
+	base rune
+	// finger палец слепой печати: 0-3 левая рука (от мизинца), 4-7 правая (от указательного)
+	finger int
+}
+
+// keystroke нажатие клавиши: пауза перед нажатием и время удержания
+type keystroke struct {
+	char  rune
+	delay time.Duration
+	dwell time.Duration
+}
+
+// typist модель печати: задержки между клавишами зависят от пар клавиш (digraph), рук и пальцев
+type typist struct {
+	mu       sync.Mutex
+	rng      *rand.Rand
+	wpm      float64
+	typoRate float64
+	layout   KeyboardLayout
+	keys     []keyPosition
+	index    map[rune]int
+}
+
+// newTypist создает модель печати (nil - настройки по умолчанию)
+func newTypist(options *TypingOptions) *typist {
+	if options == nil {
+		options = &TypingOptions{}
+	}
+	seed := options.Seed
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+
+	t := &typist{
+		rng:      rand.New(rand.NewSource(seed)),
+		wpm:      options.WPM,
+		typoRate: options.TypoRate,
+		index:    make(map[rune]int),
+	}
+	if t.wpm <= 0 {
+		t.wpm = 60
+	}
+	if t.typoRate == 0 {
+		t.typoRate = 0.02
+	}
+
+	t.layout = options.Layout
+	layout, ok := keyboardLayouts[t.layout]
+	if !ok {
+		t.layout = LayoutQWERTY
+		layout = keyboardLayouts[t.layout]
+	}
+	for row, chars := range layout.rows {
+		base := []rune(chars[0])
+		for level, line := range chars {
+			for col, char := range []rune(line) {
+				if _, ok := t.index[char]; ok {
+					continue
+				}
+				t.index[char] = len(t.keys)
+				t.keys = append(t.keys, keyPosition{
+					char:   char,
+					row:    row,
+					x:      layout.offsets[row] + float64(col),
+					shift:  level == 1,
+					code:   layout.codes[row][col],
+					base:   base[col],
+					finger: keyFinger(col - layout.home[row]),
+				})
+			}
+		}
+	}
+	return t
+}
+
+// keyFinger возвращает палец для клавиши по ее номеру от клавиши левого мизинца
+func keyFinger(col int) int {
+	switch {
+	case col <= 0:
+		return 0
+	case col <= 2:
+		return col
+	case col <= 4:
+		return 3
+	case col <= 6:
+		return 4
+	case col <= 8:
+		return col - 2
+	}
+	return 7
+}
+
+// SetTyping задает настройки ввода текста для SendKeysChar на этой странице (nil - настройки по умолчанию)
+func (p *Page) SetTyping(options *TypingOptions) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.typist = newTypist(options)
+}
+
+// typing возвращает модель печати страницы, создавая ее из BrowserOptions.Typing при первом вводе
+func (p *Page) typing() *typist {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.typist == nil {
+		p.typist = newTypist(p.browser.typingOptions())
+	}
+	return p.typist
+}
+
+// typingOptions возвращает настройки печати новой вкладки: при заданном Seed у каждой вкладки свой поток случайных чисел
+func (b *Browser) typingOptions() *TypingOptions {
+	if b.options.Typing == nil {
+		return nil
+	}
+	options := *b.options.Typing
+	if options.Seed != 0 {
+		b.seeds.mu.Lock()
+		options.Seed += b.seeds.typing
+		b.seeds.typing++
+		b.seeds.mu.Unlock()
+	}
+	return &options
+}
+
+// SendKeysChar фокусирует элемент и печатает текст как человек: keyDown/char/keyUp с паузами и редкими опечатками
+// Символы, которых нет в раскладке, вставляются через Input.insertText, как при вводе через IME
+func (p *Page) SendKeysChar(selector, text string) error {
+	if err := p.run(chromedp.Focus(selector)); err != nil {
+		return fmt.Errorf("failed to focus %s: %w", selector, err)
+	}
+	t := p.typing()
+	for _, stroke := range t.plan(text) {
+		if err := sleep(p.ctx, stroke.delay); err != nil {
+			return err
+		}
+		events, shift := t.keyEvents(stroke.char)
+		if err := p.run(typeKey(stroke.char, events, shift, stroke.dwell)); err != nil {
+			return fmt.Errorf("failed to type %q: %w", stroke.char, err)
+		}
+	}
+	return nil
+}
+
+// keyEvents возвращает события keyDown/char/keyUp символа в раскладке и нужен ли Shift (nil - вставка через insertText)
+func (t *typist) keyEvents(char rune) ([]*input.DispatchKeyEventParams, bool) {
+	if t.layout != LayoutQWERTY && char != ' ' && unicode.IsPrint(char) {
+		if pos, ok := t.position(char); ok {
+			return layoutKeyEvents(pos), pos.shift
+		}
+		return nil, false
+	}
+
+	if char == '\n' {
+		char = '\r'
+	}
+	key, ok := kb.Keys[char]
+	if !ok || char >= utf8.RuneSelf {
+		return nil, false
+	}
+	return kb.Encode(char), key.Shift
+}
+
+// usKeys клавиши US-раскладки по KeyboardEvent.code
+var usKeys = func() map[string]*kb.Key {
+	keys := make(map[string]*kb.Key)
+	for _, key := range kb.Keys {
+		if key.Code != "" && !key.Shift {
+			keys[key.Code] = key
+		}
+	}
+	return keys
+}()
+
+// layoutKeyEvents кодирует символ раскладки как kb.Encode, но с code и native кодом его физической клавиши
+func layoutKeyEvents(pos keyPosition) []*input.DispatchKeyEventParams {
+	keyDown := input.DispatchKeyEventParams{
+		Key:  string(pos.char),
+		Code: pos.code,
+	}
+	if us := usKeys[pos.code]; us != nil {
+		keyDown.NativeVirtualKeyCode = us.Native
+		keyDown.WindowsVirtualKeyCode = us.Windows
+	}
+	if upper := unicode.ToUpper(pos.char); upper >= 'A' && upper <= 'Z' {
+		keyDown.WindowsVirtualKeyCode = int64(upper)
+	}
+	if runtime.GOOS == "darwin" {
+		keyDown.NativeVirtualKeyCode = 0
+	}
+	if pos.shift {
+		keyDown.Modifiers |= input.ModifierShift
+	}
+
+	keyUp := keyDown
+	keyDown.Type, keyUp.Type = input.KeyDown, input.KeyUp
+	keyChar := keyDown
+	keyChar.Type = input.KeyChar
+	keyChar.Text = string(pos.char)
+	keyChar.UnmodifiedText = string(pos.base)
+	keyChar.NativeVirtualKeyCode = int64(pos.char)
+	keyChar.WindowsVirtualKeyCode = int64(pos.char)
+	return []*input.DispatchKeyEventParams{&keyDown, &keyChar, &keyUp}
+}
+
+// typeKey нажимает клавишу в элементе с фокусом и отпускает ее через dwell (events nil - вставка char через insertText)
+func typeKey(char rune, events []*input.DispatchKeyEventParams, shift bool, dwell time.Duration) chromedp.ActionFunc {
+	return func(ctx context.Context) (err error) {
+		if len(events) == 0 {
+			return input.InsertText(string(char)).Do(ctx)
+		}
+
+		// Клавиши отпускаются и после отмены ctx, иначе они останутся нажатыми
+		release := func(event *input.DispatchKeyEventParams) {
+			releaseCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), releaseTimeout)
+			defer cancel()
+			if releaseErr := event.Do(releaseCtx); err == nil {
+				err = releaseErr
+			}
+		}
+
+		if shift {
+			shiftEvents := kb.Encode([]rune(kb.Shift)[0])
+			shiftDown, shiftUp := shiftEvents[0], shiftEvents[len(shiftEvents)-1]
+			shiftDown.Modifiers = input.ModifierShift
+			if err := shiftDown.Do(ctx); err != nil {
+				return err
+			}
+			defer func() {
+				// Ошибку игнорируем: после отмены ctx Shift отпускается сразу
+				sleep(ctx, dwell/2)
+				release(shiftUp)
+			}()
+			if err := sleep(ctx, dwell/2); err != nil {
+				return err
+			}
+		}
+
+		if err := events[0].Do(ctx); err != nil {
+			return err
+		}
+		defer release(events[len(events)-1])
+		for _, event := range events[1 : len(events)-1] {
+			if err := event.Do(ctx); err != nil {
+				return err
+			}
+		}
+		return sleep(ctx, dwell)
+	}
+}
+
+// plan разбивает текст на нажатия клавиш, добавляя опечатки с исправлением
+func (t *typist) plan(text string) []keystroke {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	chars := []rune(text)
+	strokes := make([]keystroke, 0, len(chars))
+	var prev keystroke
+	press := func(char rune) {
+		stroke := t.stroke(prev, char)
+		if len(strokes) == 0 {
+			stroke.delay = 0
+		}
+		strokes = append(strokes, stroke)
+		prev = stroke
+	}
+
+	for i, char := range chars {
+		if typo, ok := t.typo(char); ok {
+			press(typo)
+			erase := 1
+			// Иногда опечатка замечается только после следующей буквы
+			if i+1 < len(chars) && unicode.IsLetter(chars[i+1]) && t.rng.Float64() < 0.3 {
+				press(chars[i+1])
+				erase = 2
+			}
+			for j := 0; j < erase; j++ {
+				press('\b')
+				if j == 0 {
+					// Пауза, пока человек замечает опечатку
+					strokes[len(strokes)-1].delay += t.duration(200*time.Millisecond, 600*time.Millisecond)
+				}
+			}
+		}
+		press(char)
+	}
+	return strokes
+}
+
+// stroke рассчитывает паузу перед нажатием char после prev и время удержания клавиши
+func (t *typist) stroke(prev keystroke, char rune) keystroke {
+	// Интервал между нажатиями при средней скорости: слово - 5 символов
+	base := 12000 / t.wpm
+	interval := base * t.digraph(prev.char, char) * math.Exp(t.rng.NormFloat64()*0.25)
+
+	switch prev.char {
+	case '.', ',', '!', '?', ';', ':', '\n':
+		interval += base * (0.5 + t.rng.Float64())
+	case ' ':
+		// Изредка человек задумывается перед следующим словом
+		if t.rng.Float64() < 0.05 {
+			interval += float64(t.duration(300*time.Millisecond, 900*time.Millisecond) / time.Millisecond)
+		}
+	}
+
+	dwell := (90 + t.rng.NormFloat64()*20) * math.Sqrt(60/t.wpm)
+	dwell = math.Max(40, math.Min(200, dwell))
+	// Пауза отсчитывается от отпускания предыдущей клавиши
+	delay := math.Max(15, interval-float64(prev.dwell/time.Millisecond))
+
+	return keystroke{
+		char:  char,
+		delay: time.Duration(delay * float64(time.Millisecond)),
+		dwell: time.Duration(dwell * float64(time.Millisecond)),
+	}
+}
+
+// digraph возвращает множитель интервала для пары клавиш с учетом рук, пальцев и расстояния
+func (t *typist) digraph(prev, char rune) float64 {
+	if prev == ' ' || char == ' ' {
+		// Пробел нажимается большим пальцем параллельно с остальными
+		return 0.9
+	}
+	from, ok := t.position(prev)
+	to, ok2 := t.position(char)
+	if !ok || !ok2 {
+		return 1.1
+	}
+
+	factor := 1.0
+	distance := math.Hypot(to.x-from.x, float64(to.row-from.row))
+	switch {
+	case from.char == to.char:
+		factor = 0.85
+	case (from.finger < 4) != (to.finger < 4):
+		factor = 0.75
+	case from.finger == to.finger:
+		factor = 1.3 + 0.1*distance
+	default:
+		factor = 1.0 + 0.05*distance
+	}
+	if to.shift && !from.shift {
+		factor += 0.25
+	}
+	return factor
+}
+
+// position возвращает положение символа в раскладке
+func (t *typist) position(char rune) (keyPosition, bool) {
+	i, ok := t.index[char]
+	if !ok {
+		return keyPosition{}, false
+	}
+	return t.keys[i], true
+}
+
+// typo с вероятностью typoRate возвращает букву соседней клавиши вместо char
+func (t *typist) typo(char rune) (rune, bool) {
+	if t.typoRate <= 0 || !unicode.IsLetter(char) || t.rng.Float64() >= t.typoRate {
+		return 0, false
+	}
+	from, ok := t.position(char)
+	if !ok {
+		return 0, false
+	}
+
+	var neighbors []rune
+	for _, key := range t.keys {
+		if key.char == char || key.shift != from.shift || !unicode.IsLetter(key.char) {
+			continue
+		}
+		if math.Abs(float64(key.row-from.row)) <= 1 && math.Hypot(key.x-from.x, float64(key.row-from.row)) <= 1.2 {
+			neighbors = append(neighbors, key.char)
+		}
+	}
+	if len(neighbors) == 0 {
+		return 0, false
+	}
+	return neighbors[t.rng.Intn(len(neighbors))], true
+}
+
+// duration возвращает случайную длительность в диапазоне [min, max)
+func (t *typist) duration(min, max time.Duration) time.Duration {
+	return min + time.Duration(t.rng.Int63n(int64(max-min)))
+}
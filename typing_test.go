@@ -0,0 +1,96 @@
+package osciris
+
+import (
+	"reflect"
+	"testing"
+)
+
+// typed применяет нажатия к пустому полю ввода
+func typed(strokes []keystroke) string {
+	var text []rune
+	for _, stroke := range strokes {
+		if stroke.char == '\b' {
+			if len(text) > 0 {
+				text = text[:len(text)-1]
+			}
+			continue
+		}
+		text = append(text, stroke.char)
+	}
+	return string(text)
+}
+
+func TestTypistPlanDeterministic(t *testing.T) {
+	const text = "The quick brown fox jumps over the lazy dog."
+
+	for _, layout := range []KeyboardLayout{LayoutQWERTY, LayoutQWERTZ, LayoutAZERTY} {
+		options := &TypingOptions{Seed: 7, TypoRate: 0.2, Layout: layout}
+		a := newTypist(options).plan(text)
+		b := newTypist(options).plan(text)
+		if !reflect.DeepEqual(a, b) {
+			t.Fatalf("%s: plans differ for the same seed", layout)
+		}
+	}
+}
+
+func TestTypistPlanCorrectsTypos(t *testing.T) {
+	const text = "Съешь же ещё этих мягких французских булок, да выпей чаю"
+
+	typos := 0
+	for seed := int64(1); seed <= 20; seed++ {
+		strokes := newTypist(&TypingOptions{Seed: seed, TypoRate: 0.3, Layout: LayoutJCUKEN}).plan(text)
+		for _, stroke := range strokes {
+			if stroke.char == '\b' {
+				typos++
+			}
+		}
+		if got := typed(strokes); got != text {
+			t.Fatalf("seed %d: typed %q, want %q", seed, got, text)
+		}
+	}
+	if typos == 0 {
+		t.Fatal("no typos were made")
+	}
+}
+
+func TestTypistLayoutKeyCodes(t *testing.T) {
+	tests := []struct {
+		layout KeyboardLayout
+		char   rune
+		code   string
+		shift  bool
+	}{
+		{LayoutQWERTY, 'z', "KeyZ", false},
+		{LayoutQWERTZ, 'z', "KeyY", false},
+		{LayoutQWERTZ, 'Y', "KeyZ", true},
+		{LayoutQWERTZ, '-', "Slash", false},
+		{LayoutAZERTY, 'a', "KeyQ", false},
+		{LayoutAZERTY, 'w', "KeyZ", false},
+		{LayoutAZERTY, 'm', "Semicolon", false},
+		{LayoutAZERTY, '1', "Digit1", true},
+		{LayoutJCUKEN, 'й', "KeyQ", false},
+	}
+	for _, tt := range tests {
+		events, shift := newTypist(&TypingOptions{Layout: tt.layout}).keyEvents(tt.char)
+		if len(events) == 0 {
+			t.Fatalf("%s %q: no key events", tt.layout, tt.char)
+		}
+		if events[0].Code != tt.code || shift != tt.shift {
+			t.Errorf("%s %q: code %s shift %v, want %s shift %v", tt.layout, tt.char, events[0].Code, shift, tt.code, tt.shift)
+		}
+	}
+}
+
+func TestTypingOptionsSeedPerTab(t *testing.T) {
+	options := &BrowserOptions{Typing: &TypingOptions{Seed: 7}}
+	parent := &Browser{options: options, seeds: &tabSeeds{}}
+	tab := &Browser{options: options, seeds: parent.seeds}
+
+	var seeds []int64
+	for _, b := range []*Browser{parent, tab, parent} {
+		seeds = append(seeds, b.typingOptions().Seed)
+	}
+	if want := []int64{7, 8, 9}; !reflect.DeepEqual(seeds, want) {
+		t.Errorf("seeds = %v, want %v", seeds, want)
+	}
+}
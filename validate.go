@@ -0,0 +1,396 @@
+package osciris
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	fp "github.com/vitaliitsarov/fingerprint-injector-go"
+)
+
+// IssueSeverity уровень проблемы fingerprint
+type IssueSeverity string
+
+const (
+	// IssueError противоречие, которое сайты обнаруживают напрямую
+	IssueError IssueSeverity = "error"
+	// IssueWarning редкое или подозрительное сочетание значений
+	IssueWarning IssueSeverity = "warning"
+)
+
+// Issue проблема, найденная при проверке fingerprint
+type Issue struct {
+	Severity IssueSeverity
+	// Field поле fingerprint или опций (например, "Platform", "Screen.Width")
+	Field   string
+	Message string
+}
+
+// String возвращает описание проблемы в формате "error: Platform: ..."
+func (i Issue) String() string {
+	return fmt.Sprintf("%s: %s: %s", i.Severity, i.Field, i.Message)
+}
+
+// fingerprintOS операционная система, определенная по User-Agent
+type fingerprintOS string
+
+const (
+	osUnknown fingerprintOS = ""
+	osWindows fingerprintOS = "Windows"
+	osMacOS   fingerprintOS = "macOS"
+	osLinux   fingerprintOS = "Linux"
+	osAndroid fingerprintOS = "Android"
+	osIOS     fingerprintOS = "iOS"
+)
+
+// chromeMaxDeviceMemory максимальное значение navigator.deviceMemory в Chrome
+const chromeMaxDeviceMemory = 8
+
+// countryTimezones часовые пояса (ID или префиксы ID), типичные для страны из Language
+var countryTimezones = map[string][]string{
+	"US": {"America/", "Pacific/Honolulu"},
+	"CA": {"America/"},
+	"MX": {"America/"},
+	"BR": {"America/"},
+	"AR": {"America/"},
+	"GB": {"Europe/London"},
+	"IE": {"Europe/Dublin"},
+	"DE": {"Europe/Berlin"},
+	"AT": {"Europe/Vienna"},
+	"CH": {"Europe/Zurich"},
+	"FR": {"Europe/Paris"},
+	"ES": {"Europe/Madrid", "Atlantic/Canary"},
+	"IT": {"Europe/Rome"},
+	"NL": {"Europe/Amsterdam"},
+	"PL": {"Europe/Warsaw"},
+	"UA": {"Europe/Kiev", "Europe/Kyiv"},
+	"BY": {"Europe/Minsk"},
+	"RU": {"Europe/Moscow", "Europe/Kaliningrad", "Europe/Samara", "Europe/Volgograd", "Europe/Saratov", "Europe/Ulyanovsk", "Europe/Astrakhan", "Europe/Kirov", "Asia/"},
+	"KZ": {"Asia/Almaty", "Asia/Qostanay", "Asia/Aqtobe", "Asia/Aqtau", "Asia/Atyrau", "Asia/Oral", "Asia/Qyzylorda"},
+	"TR": {"Europe/Istanbul"},
+	"JP": {"Asia/Tokyo"},
+	"KR": {"Asia/Seoul"},
+	"CN": {"Asia/Shanghai", "Asia/Urumqi"},
+	"IN": {"Asia/Kolkata", "Asia/Calcutta"},
+	"AU": {"Australia/"},
+}
+
+// ValidateFingerprint проверяет fingerprint на внутренние противоречия:
+// User-Agent и Platform/Vendor, WebGL и платформа, экран, часовой пояс и язык, параметры железа
+func ValidateFingerprint(f *fp.Fingerprint) []Issue {
+	if f == nil {
+		return []Issue{{Severity: IssueError, Field: "Fingerprint", Message: "fingerprint is nil"}}
+	}
+
+	var issues []Issue
+	add := func(severity IssueSeverity, field, format string, args ...interface{}) {
+		issues = append(issues, Issue{Severity: severity, Field: field, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if f.UserAgent == "" {
+		add(IssueError, "UserAgent", "user agent is empty")
+	}
+	uaOS := userAgentOS(f.UserAgent)
+	mobile := uaOS == osAndroid || uaOS == osIOS
+	// Планшеты Android не указывают "Mobile" в User-Agent
+	tablet := mobile && (strings.Contains(f.UserAgent, "iPad") || !strings.Contains(f.UserAgent, "Mobile"))
+
+	// Platform должен соответствовать ОС из User-Agent
+	if f.Platform != "" && uaOS != osUnknown {
+		expected := map[fingerprintOS]string{
+			osWindows: "Win32",
+			osMacOS:   "MacIntel",
+			osLinux:   "Linux x86_64",
+			osAndroid: "Linux armv8l",
+			osIOS:     "iPhone",
+		}[uaOS]
+		if !platformMatches(uaOS, f.Platform) {
+			add(IssueError, "Platform", "platform %q contradicts %s user agent (expected %q)", f.Platform, uaOS, expected)
+		}
+	}
+
+	// Vendor зависит от движка браузера
+	if strings.Contains(f.UserAgent, "Chrome/") && !strings.Contains(f.UserAgent, "Edg/") && f.Vendor != "" && f.Vendor != "Google Inc." {
+		add(IssueError, "Vendor", "vendor %q contradicts Chrome user agent (expected \"Google Inc.\")", f.Vendor)
+	}
+	if strings.Contains(f.UserAgent, "Chrome/") && f.Vendor == "" {
+		add(IssueWarning, "Vendor", "vendor is empty, Chrome reports \"Google Inc.\"")
+	}
+
+	// Language должен совпадать с первым элементом Languages
+	if f.Language == "" {
+		add(IssueWarning, "Language", "language is empty")
+	}
+	if len(f.Languages) > 0 && f.Language != "" && f.Languages[0] != f.Language {
+		add(IssueError, "Languages", "navigator.languages[0] is %q, but navigator.language is %q", f.Languages[0], f.Language)
+	}
+
+	issues = append(issues, validateScreen(f.Screen, mobile, tablet)...)
+	issues = append(issues, validateWebGL(f.WebGL, uaOS)...)
+	issues = append(issues, validateTimezone(f.Timezone, f.Language)...)
+
+	// Параметры железа
+	if f.HardwareConcurrency <= 0 {
+		add(IssueWarning, "HardwareConcurrency", "hardware concurrency is not set")
+	} else if f.HardwareConcurrency > 64 {
+		add(IssueWarning, "HardwareConcurrency", "%d logical cores is unusual for a consumer device", f.HardwareConcurrency)
+	} else if f.HardwareConcurrency > 1 && f.HardwareConcurrency%2 != 0 {
+		add(IssueWarning, "HardwareConcurrency", "odd number of logical cores (%d) is rare", f.HardwareConcurrency)
+	}
+	if mobile && f.HardwareConcurrency > 12 {
+		add(IssueWarning, "HardwareConcurrency", "%d logical cores is unusual for a mobile device", f.HardwareConcurrency)
+	}
+
+	switch {
+	case f.DeviceMemory == 0:
+		add(IssueWarning, "DeviceMemory", "device memory is not set")
+	case f.DeviceMemory > chromeMaxDeviceMemory:
+		add(IssueError, "DeviceMemory", "Chrome reports at most %d GB, got %d", chromeMaxDeviceMemory, f.DeviceMemory)
+	case f.DeviceMemory&(f.DeviceMemory-1) != 0:
+		add(IssueError, "DeviceMemory", "Chrome reports a power of two, got %d", f.DeviceMemory)
+	}
+
+	if f.Canvas != nil && f.Canvas.Noise > 0.1 {
+		add(IssueWarning, "Canvas.Noise", "noise %.2f may produce visible artifacts", f.Canvas.Noise)
+	}
+
+	return issues
+}
+
+// ValidateBrowserOptions проверяет Fingerprint опций и его согласованность с размерами окна и профилем
+func ValidateBrowserOptions(options *BrowserOptions) []Issue {
+	if options == nil {
+		options = DefaultBrowserOptions()
+	}
+	if options.Profile != nil {
+		options = withProfile(options, options.Profile)
+	}
+
+	issues := ValidateFingerprint(options.Fingerprint)
+	f := options.Fingerprint
+	if f == nil {
+		return issues
+	}
+
+	if s := f.Screen; s != nil && s.Width > 0 && s.Height > 0 {
+		if options.WindowWidth > s.Width || options.WindowHeight > s.Height {
+			issues = append(issues, Issue{
+				Severity: IssueError,
+				Field:    "WindowWidth",
+				Message:  fmt.Sprintf("window %dx%d is larger than screen %dx%d", options.WindowWidth, options.WindowHeight, s.Width, s.Height),
+			})
+		}
+	}
+
+	if p := options.Profile; p != nil && p.Timezone != "" && f.Timezone != nil && f.Timezone.ID != "" && p.Timezone != f.Timezone.ID {
+		issues = append(issues, Issue{
+			Severity: IssueError,
+			Field:    "Profile.Timezone",
+			Message:  fmt.Sprintf("profile timezone %q differs from fingerprint timezone %q", p.Timezone, f.Timezone.ID),
+		})
+	}
+	if p := options.Profile; p != nil && p.Locale != "" && f.Language != "" && p.Locale != f.Language {
+		issues = append(issues, Issue{
+			Severity: IssueWarning,
+			Field:    "Profile.Locale",
+			Message:  fmt.Sprintf("profile locale %q differs from fingerprint language %q", p.Locale, f.Language),
+		})
+	}
+
+	return issues
+}
+
+// validateScreen проверяет размеры и глубину цвета экрана
+func validateScreen(s *fp.Screen, mobile, tablet bool) []Issue {
+	if s == nil {
+		return []Issue{{Severity: IssueWarning, Field: "Screen", Message: "screen is not set, real screen values will leak"}}
+	}
+
+	var issues []Issue
+	add := func(severity IssueSeverity, field, format string, args ...interface{}) {
+		issues = append(issues, Issue{Severity: severity, Field: field, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if s.Width <= 0 || s.Height <= 0 {
+		add(IssueError, "Screen.Width", "screen size %dx%d is invalid", s.Width, s.Height)
+		return issues
+	}
+	if s.AvailWidth > s.Width || s.AvailHeight > s.Height {
+		add(IssueError, "Screen.AvailWidth", "available area %dx%d is larger than screen %dx%d", s.AvailWidth, s.AvailHeight, s.Width, s.Height)
+	}
+	if s.AvailWidth == 0 || s.AvailHeight == 0 {
+		add(IssueWarning, "Screen.AvailWidth", "available area is not set")
+	}
+	if s.ColorDepth != 0 && s.ColorDepth != 24 && s.ColorDepth != 30 && s.ColorDepth != 32 {
+		add(IssueWarning, "Screen.ColorDepth", "color depth %d is unusual", s.ColorDepth)
+	}
+	if s.PixelDepth != 0 && s.ColorDepth != 0 && s.PixelDepth != s.ColorDepth {
+		add(IssueError, "Screen.PixelDepth", "pixel depth %d differs from color depth %d", s.PixelDepth, s.ColorDepth)
+	}
+	if s.DevicePixelRatio <= 0 {
+		add(IssueWarning, "Screen.DevicePixelRatio", "device pixel ratio is not set")
+	}
+
+	switch {
+	case tablet:
+		if s.Width > 1600 || s.Height > 1600 {
+			add(IssueWarning, "Screen.Width", "screen %dx%d is unusually large for a tablet (CSS pixels)", s.Width, s.Height)
+		}
+	case mobile:
+		if s.Width > s.Height {
+			add(IssueWarning, "Screen.Width", "landscape screen %dx%d is unusual for a mobile user agent", s.Width, s.Height)
+		}
+		if s.Width > 1024 {
+			add(IssueError, "Screen.Width", "screen width %d is too large for a phone (CSS pixels)", s.Width)
+		}
+	case s.Width < 800:
+		add(IssueWarning, "Screen.Width", "screen width %d is unusual for a desktop user agent", s.Width)
+	}
+	if mobile && s.DevicePixelRatio > 0 && s.DevicePixelRatio < 1.5 {
+		add(IssueWarning, "Screen.DevicePixelRatio", "device pixel ratio %.2f is unusual for a mobile device", s.DevicePixelRatio)
+	}
+
+	return issues
+}
+
+// validateWebGL проверяет соответствие WebGL renderer платформе
+func validateWebGL(w *fp.WebGL, uaOS fingerprintOS) []Issue {
+	if w == nil || w.Renderer == "" || uaOS == osUnknown {
+		return nil
+	}
+
+	renderer := strings.ToLower(w.Renderer)
+	has := func(parts ...string) bool {
+		for _, part := range parts {
+			if strings.Contains(renderer, part) {
+				return true
+			}
+		}
+		return false
+	}
+
+	direct3D := has("direct3d", "d3d11", "d3d9")
+	apple := has("apple", "metal")
+	mesa := has("mesa", "llvmpipe", "swiftshader")
+	mobileGPU := has("mali", "adreno", "powervr")
+
+	var conflict string
+	switch uaOS {
+	case osWindows:
+		if apple || mesa {
+			conflict = "Apple/Mesa renderer"
+		} else if mobileGPU {
+			return []Issue{{Severity: IssueWarning, Field: "WebGL.Renderer", Message: fmt.Sprintf("mobile GPU %q is rare on Windows", w.Renderer)}}
+		}
+	case osMacOS:
+		if direct3D || mesa || mobileGPU {
+			conflict = "Direct3D/Mesa/mobile renderer"
+		}
+	case osLinux:
+		if direct3D || apple || mobileGPU {
+			conflict = "Direct3D/Apple/mobile renderer"
+		}
+	case osAndroid:
+		if direct3D || apple {
+			conflict = "Direct3D/Apple renderer"
+		} else if has("geforce", "radeon", "intel(r)", "intel, ") {
+			return []Issue{{Severity: IssueWarning, Field: "WebGL.Renderer", Message: fmt.Sprintf("desktop GPU %q is unusual on Android", w.Renderer)}}
+		}
+	case osIOS:
+		if !apple {
+			conflict = "non-Apple renderer"
+		}
+	}
+
+	var issues []Issue
+	if conflict != "" {
+		issues = append(issues, Issue{Severity: IssueError, Field: "WebGL.Renderer", Message: fmt.Sprintf("%s %q contradicts %s user agent", conflict, w.Renderer, uaOS)})
+	}
+	if strings.HasPrefix(strings.ToLower(w.Vendor), "apple") && uaOS != osMacOS && uaOS != osIOS {
+		issues = append(issues, Issue{Severity: IssueError, Field: "WebGL.Vendor", Message: fmt.Sprintf("vendor %q contradicts %s user agent", w.Vendor, uaOS)})
+	}
+	return issues
+}
+
+// validateTimezone проверяет ID и смещение часового пояса и их соответствие стране из language
+func validateTimezone(tz *fp.Timezone, language string) []Issue {
+	if tz == nil || tz.ID == "" {
+		return []Issue{{Severity: IssueWarning, Field: "Timezone", Message: "timezone is not set, host timezone will leak"}}
+	}
+
+	var issues []Issue
+
+	loc, err := time.LoadLocation(tz.ID)
+	if err != nil {
+		issues = append(issues, Issue{Severity: IssueWarning, Field: "Timezone.ID", Message: fmt.Sprintf("unknown timezone %q", tz.ID)})
+	} else {
+		// getTimezoneOffset() = -(смещение от UTC в минутах); допускаем и зимнее, и летнее время
+		year := time.Now().Year()
+		var offsets []int
+		for _, month := range []time.Month{time.January, time.July} {
+			_, seconds := time.Date(year, month, 1, 12, 0, 0, 0, loc).Zone()
+			offsets = append(offsets, -seconds/60)
+		}
+		if tz.Offset != offsets[0] && tz.Offset != offsets[1] {
+			issues = append(issues, Issue{
+				Severity: IssueError,
+				Field:    "Timezone.Offset",
+				Message:  fmt.Sprintf("offset %d does not match %s (getTimezoneOffset() is %d or %d)", tz.Offset, tz.ID, offsets[0], offsets[1]),
+			})
+		}
+	}
+
+	// Английский используется по всему миру, поэтому проверяем только остальные языки
+	lang, country, ok := strings.Cut(language, "-")
+	if !ok || strings.EqualFold(lang, "en") {
+		return issues
+	}
+	zones, known := countryTimezones[strings.ToUpper(country)]
+	if !known {
+		return issues
+	}
+	for _, zone := range zones {
+		if tz.ID == zone || (strings.HasSuffix(zone, "/") && strings.HasPrefix(tz.ID, zone)) {
+			return issues
+		}
+	}
+	return append(issues, Issue{
+		Severity: IssueWarning,
+		Field:    "Timezone.ID",
+		Message:  fmt.Sprintf("timezone %q is unusual for language %q", tz.ID, language),
+	})
+}
+
+// userAgentOS определяет операционную систему по User-Agent
+func userAgentOS(userAgent string) fingerprintOS {
+	switch {
+	case strings.Contains(userAgent, "iPhone"), strings.Contains(userAgent, "iPad"):
+		return osIOS
+	case strings.Contains(userAgent, "Android"):
+		return osAndroid
+	case strings.Contains(userAgent, "Windows"):
+		return osWindows
+	case strings.Contains(userAgent, "Macintosh"), strings.Contains(userAgent, "Mac OS X"):
+		return osMacOS
+	case strings.Contains(userAgent, "Linux"), strings.Contains(userAgent, "X11"):
+		return osLinux
+	}
+	return osUnknown
+}
+
+// platformMatches проверяет, что navigator.platform соответствует ОС
+func platformMatches(uaOS fingerprintOS, platform string) bool {
+	switch uaOS {
+	case osWindows:
+		return platform == "Win32" || platform == "Win64"
+	case osMacOS:
+		return platform == "MacIntel"
+	case osLinux:
+		return strings.HasPrefix(platform, "Linux") && !strings.Contains(platform, "arm") && !strings.Contains(platform, "aarch64")
+	case osAndroid:
+		return strings.HasPrefix(platform, "Linux")
+	case osIOS:
+		return platform == "iPhone" || platform == "iPad" || platform == "iPod"
+	}
+	return true
+}
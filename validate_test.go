@@ -0,0 +1,147 @@
+package osciris
+
+import (
+	"testing"
+
+	fp "github.com/vitaliitsarov/fingerprint-injector-go"
+)
+
+const (
+	testWindowsUA = "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/139.0.0.0 Safari/537.36"
+	testAndroidUA = "Mozilla/5.0 (Linux; Android 10; K) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/139.0.0.0 Mobile Safari/537.36"
+)
+
+// hasIssue возвращает true, если среди issues есть проблема поля field с уровнем severity
+func hasIssue(issues []Issue, severity IssueSeverity, field string) bool {
+	for _, issue := range issues {
+		if issue.Severity == severity && issue.Field == field {
+			return true
+		}
+	}
+	return false
+}
+
+// testFingerprint возвращает непротиворечивый fingerprint Windows
+func testFingerprint() *fp.Fingerprint {
+	return &fp.Fingerprint{
+		UserAgent: testWindowsUA,
+		Platform:  "Win32",
+		Vendor:    "Google Inc.",
+		Language:  "de-DE",
+		Languages: []string{"de-DE", "de"},
+		Screen: &fp.Screen{
+			Width: 1920, Height: 1080, AvailWidth: 1920, AvailHeight: 1040,
+			ColorDepth: 24, PixelDepth: 24, DevicePixelRatio: 1,
+		},
+		Timezone:            &fp.Timezone{ID: "Europe/Berlin", Offset: -60},
+		WebGL:               &fp.WebGL{Vendor: "Google Inc. (NVIDIA)", Renderer: "ANGLE (NVIDIA, NVIDIA GeForce RTX 3060 Direct3D11 vs_5_0 ps_5_0, D3D11)"},
+		HardwareConcurrency: 8,
+		DeviceMemory:        8,
+	}
+}
+
+func TestValidateFingerprint(t *testing.T) {
+	tests := []struct {
+		name     string
+		modify   func(f *fp.Fingerprint)
+		severity IssueSeverity
+		field    string
+	}{
+		{"platform contradicts user agent", func(f *fp.Fingerprint) { f.Platform = "MacIntel" }, IssueError, "Platform"},
+		{"android platform", func(f *fp.Fingerprint) { f.UserAgent, f.Platform = testAndroidUA, "Win32" }, IssueError, "Platform"},
+		{"vendor contradicts chrome", func(f *fp.Fingerprint) { f.Vendor = "Apple Computer, Inc." }, IssueError, "Vendor"},
+		{"languages mismatch", func(f *fp.Fingerprint) { f.Languages = []string{"en-US"} }, IssueError, "Languages"},
+		{"device memory above chrome cap", func(f *fp.Fingerprint) { f.DeviceMemory = 16 }, IssueError, "DeviceMemory"},
+		{"device memory not power of two", func(f *fp.Fingerprint) { f.DeviceMemory = 6 }, IssueError, "DeviceMemory"},
+		{"odd core count", func(f *fp.Fingerprint) { f.HardwareConcurrency = 7 }, IssueWarning, "HardwareConcurrency"},
+		{"apple renderer on windows", func(f *fp.Fingerprint) { f.WebGL.Renderer = "Apple M1" }, IssueError, "WebGL.Renderer"},
+		{"phone screen too wide", func(f *fp.Fingerprint) {
+			f.UserAgent, f.Platform = testAndroidUA, "Linux armv8l"
+			f.Screen.Width, f.Screen.Height = 1280, 800
+		}, IssueError, "Screen.Width"},
+	}
+
+	if issues := ValidateFingerprint(testFingerprint()); len(issues) != 0 {
+		t.Fatalf("consistent fingerprint: unexpected issues %v", issues)
+	}
+	for _, tt := range tests {
+		f := testFingerprint()
+		tt.modify(f)
+		if issues := ValidateFingerprint(f); !hasIssue(issues, tt.severity, tt.field) {
+			t.Errorf("%s: want %s on %s, got %v", tt.name, tt.severity, tt.field, issues)
+		}
+	}
+}
+
+func TestValidateDevices(t *testing.T) {
+	for _, d := range Devices {
+		for _, landscape := range []bool{false, true} {
+			d.Landscape = landscape
+			for _, issue := range ValidateFingerprint(d.Fingerprint()) {
+				if issue.Severity == IssueError {
+					t.Errorf("%s (landscape %v): %s", d.Name, landscape, issue)
+				}
+			}
+		}
+	}
+}
+
+func TestValidateTimezone(t *testing.T) {
+	tests := []struct {
+		name     string
+		tz       *fp.Timezone
+		language string
+		severity IssueSeverity
+		field    string
+	}{
+		{"not set", nil, "en-US", IssueWarning, "Timezone"},
+		{"unknown id", &fp.Timezone{ID: "Mars/Olympus"}, "en-US", IssueWarning, "Timezone.ID"},
+		{"wrong offset", &fp.Timezone{ID: "Europe/Berlin", Offset: 300}, "de-DE", IssueError, "Timezone.Offset"},
+		{"unusual for language", &fp.Timezone{ID: "Asia/Tokyo", Offset: -540}, "de-DE", IssueWarning, "Timezone.ID"},
+	}
+	for _, tt := range tests {
+		if issues := validateTimezone(tt.tz, tt.language); !hasIssue(issues, tt.severity, tt.field) {
+			t.Errorf("%s: want %s on %s, got %v", tt.name, tt.severity, tt.field, issues)
+		}
+	}
+
+	valid := []struct {
+		tz       *fp.Timezone
+		language string
+	}{
+		{&fp.Timezone{ID: "Europe/Berlin", Offset: -60}, "de-DE"},
+		{&fp.Timezone{ID: "Europe/Berlin", Offset: -120}, "de-DE"},
+		{&fp.Timezone{ID: "America/New_York", Offset: 300}, "en-GB"},
+		{&fp.Timezone{ID: "Asia/Novosibirsk", Offset: -420}, "ru-RU"},
+	}
+	for _, tt := range valid {
+		if issues := validateTimezone(tt.tz, tt.language); len(issues) != 0 {
+			t.Errorf("%s %s: unexpected issues %v", tt.tz.ID, tt.language, issues)
+		}
+	}
+}
+
+func TestPlatformMatches(t *testing.T) {
+	tests := []struct {
+		os       fingerprintOS
+		platform string
+		want     bool
+	}{
+		{osWindows, "Win32", true},
+		{osWindows, "MacIntel", false},
+		{osMacOS, "MacIntel", true},
+		{osLinux, "Linux x86_64", true},
+		{osLinux, "Linux armv8l", false},
+		{osAndroid, "Linux armv8l", true},
+		{osAndroid, "Linux aarch64", true},
+		{osAndroid, "Win32", false},
+		{osIOS, "iPhone", true},
+		{osIOS, "MacIntel", false},
+		{osUnknown, "anything", true},
+	}
+	for _, tt := range tests {
+		if got := platformMatches(tt.os, tt.platform); got != tt.want {
+			t.Errorf("platformMatches(%q, %q) = %v, want %v", tt.os, tt.platform, got, tt.want)
+		}
+	}
+}
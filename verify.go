@@ -0,0 +1,287 @@
+package osciris
+
+import (
+	"context"
+	_ "embed"
+	"fmt"
+	"reflect"
+	"time"
+
+	"github.com/chromedp/chromedp"
+)
+
+// probeURL адрес проверочной страницы; ответ подставляется через Fetch, поэтому сеть не нужна
+const probeURL = "https://osciris-probe.invalid/"
+
+//go:embed assets/probe.html
+var probePage []byte
+
+// ProbeScope значения, общие для страницы и воркера
+type ProbeScope struct {
+	UserAgent           string   `json:"userAgent"`
+	Platform            string   `json:"platform"`
+	Language            string   `json:"language"`
+	Languages           []string `json:"languages"`
+	HardwareConcurrency int      `json:"hardwareConcurrency"`
+	DeviceMemory        float64  `json:"deviceMemory"`
+	Webdriver           bool     `json:"webdriver"`
+	Timezone            string   `json:"timezone"`
+	TimezoneOffset      int      `json:"timezoneOffset"`
+	// CanvasHash SHA-256 пикселей рисунка на canvas (в воркере - OffscreenCanvas без шума, пусто без OffscreenCanvas)
+	CanvasHash string `json:"canvasHash"`
+}
+
+// ProbeScreen значения window.screen
+type ProbeScreen struct {
+	Width            int     `json:"width"`
+	Height           int     `json:"height"`
+	AvailWidth       int     `json:"availWidth"`
+	AvailHeight      int     `json:"availHeight"`
+	ColorDepth       int     `json:"colorDepth"`
+	PixelDepth       int     `json:"pixelDepth"`
+	DevicePixelRatio float64 `json:"devicePixelRatio"`
+}
+
+// ProbeBrand бренд из navigator.userAgentData
+type ProbeBrand struct {
+	Brand   string `json:"brand"`
+	Version string `json:"version"`
+}
+
+// ProbeClientHints значения navigator.userAgentData
+type ProbeClientHints struct {
+	Brands          []ProbeBrand `json:"brands"`
+	Mobile          bool         `json:"mobile"`
+	Platform        string       `json:"platform"`
+	Architecture    string       `json:"architecture"`
+	Bitness         string       `json:"bitness"`
+	Model           string       `json:"model"`
+	PlatformVersion string       `json:"platformVersion"`
+	FullVersionList []ProbeBrand `json:"fullVersionList"`
+}
+
+// Probe значения, прочитанные проверочной страницей
+type Probe struct {
+	ProbeScope
+
+	Vendor        string            `json:"vendor"`
+	Screen        ProbeScreen       `json:"screen"`
+	WebGLVendor   string            `json:"webglVendor"`
+	WebGLRenderer string            `json:"webglRenderer"`
+	UserAgentData *ProbeClientHints `json:"userAgentData"`
+	// CanvasHashRepeat хеш повторной отрисовки: шум canvas должен быть стабильным
+	CanvasHashRepeat string `json:"canvasHashRepeat"`
+
+	// Worker значения в dedicated worker (nil, если воркер не запустился)
+	Worker *ProbeScope `json:"worker"`
+}
+
+// Mismatch расхождение между ожидаемым и прочитанным значением
+type Mismatch struct {
+	Field    string
+	Expected interface{}
+	Actual   interface{}
+}
+
+// String возвращает описание расхождения
+func (m Mismatch) String() string {
+	return fmt.Sprintf("%s: expected %v, got %v", m.Field, m.Expected, m.Actual)
+}
+
+// Report результат проверки fingerprint
+type Report struct {
+	Probe      *Probe
+	Mismatches []Mismatch
+}
+
+// OK возвращает true, если расхождений нет
+func (r *Report) OK() bool {
+	return len(r.Mismatches) == 0
+}
+
+// VerifyFingerprint открывает встроенную проверочную страницу в новой вкладке и сравнивает значения
+// navigator, screen, часового пояса, WebGL, canvas, client hints и воркера с BrowserOptions.Fingerprint
+// Страница подставляется через Fetch, поэтому проверка не требует сети
+func (b *Browser) VerifyFingerprint(ctx context.Context) (*Report, error) {
+	p, err := b.NewTab("")
+	if err != nil {
+		return nil, err
+	}
+	defer p.Close()
+
+	stop, err := p.Intercept([]RequestPattern{{URL: probeURL + "*"}}, func(req *InterceptedRequest) {
+		req.Fulfill(200, map[string]string{"Content-Type": "text/html; charset=utf-8"}, probePage)
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer stop()
+
+	// Отмена ctx прерывает проверку
+	runCtx, cancel := context.WithTimeout(p.ctx, b.options.Timeout)
+	defer cancel()
+	defer context.AfterFunc(ctx, cancel)()
+
+	probe := &Probe{}
+	err = chromedp.Run(runCtx,
+		chromedp.Navigate(probeURL),
+		chromedp.Evaluate(`window.__oscirisProbe()`, probe, awaitPromise),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to run fingerprint probe: %w", err)
+	}
+
+	return &Report{
+		Probe:      probe,
+		Mismatches: b.diffProbe(probe),
+	}, nil
+}
+
+// diffProbe сравнивает прочитанные значения с fingerprint и профилем браузера
+func (b *Browser) diffProbe(probe *Probe) []Mismatch {
+	var mismatches []Mismatch
+	check := func(field string, expected, actual interface{}) {
+		if !reflect.DeepEqual(expected, actual) {
+			mismatches = append(mismatches, Mismatch{Field: field, Expected: expected, Actual: actual})
+		}
+	}
+
+	if probe.Webdriver {
+		check("navigator.webdriver", false, true)
+	}
+
+	f := b.options.Fingerprint
+	if f == nil {
+		return mismatches
+	}
+
+	if f.UserAgent != "" {
+		check("navigator.userAgent", f.UserAgent, probe.UserAgent)
+	}
+	if f.Platform != "" {
+		check("navigator.platform", f.Platform, probe.Platform)
+	}
+	if f.Vendor != "" {
+		check("navigator.vendor", f.Vendor, probe.Vendor)
+	}
+
+	language, languages := f.Language, f.Languages
+	if p := b.options.Profile; p != nil && p.Locale != "" {
+		language = p.Locale
+	}
+	if language != "" {
+		check("navigator.language", language, probe.Language)
+	}
+	if len(languages) > 0 {
+		check("navigator.languages", languages, probe.Languages)
+	}
+	if f.HardwareConcurrency > 0 {
+		check("navigator.hardwareConcurrency", f.HardwareConcurrency, probe.HardwareConcurrency)
+	}
+	if f.DeviceMemory > 0 {
+		check("navigator.deviceMemory", float64(f.DeviceMemory), probe.DeviceMemory)
+	}
+
+	if s := f.Screen; s != nil {
+		if s.Width > 0 {
+			check("screen.width", s.Width, probe.Screen.Width)
+			check("screen.height", s.Height, probe.Screen.Height)
+		}
+		if s.AvailWidth > 0 {
+			check("screen.availWidth", s.AvailWidth, probe.Screen.AvailWidth)
+			check("screen.availHeight", s.AvailHeight, probe.Screen.AvailHeight)
+		}
+		if s.ColorDepth > 0 {
+			check("screen.colorDepth", s.ColorDepth, probe.Screen.ColorDepth)
+		}
+		if s.PixelDepth > 0 {
+			check("screen.pixelDepth", s.PixelDepth, probe.Screen.PixelDepth)
+		}
+		if s.DevicePixelRatio > 0 {
+			check("window.devicePixelRatio", s.DevicePixelRatio, probe.Screen.DevicePixelRatio)
+		}
+	}
+
+	timezone := ""
+	if f.Timezone != nil {
+		timezone = f.Timezone.ID
+	}
+	if p := b.options.Profile; p != nil && p.Timezone != "" {
+		timezone = p.Timezone
+	}
+	if timezone != "" {
+		check("Intl timeZone", timezone, probe.Timezone)
+	}
+	// Смещение берется на момент проверки, поэтому учитывает летнее время
+	if loc, err := time.LoadLocation(timezone); timezone != "" && err == nil {
+		_, seconds := time.Now().In(loc).Zone()
+		check("Date.getTimezoneOffset()", -seconds/60, probe.TimezoneOffset)
+	} else if f.Timezone != nil && f.Timezone.ID == timezone && f.Timezone.ID != "" {
+		check("Date.getTimezoneOffset()", f.Timezone.Offset, probe.TimezoneOffset)
+	}
+
+	if w := f.WebGL; w != nil {
+		if w.Vendor != "" {
+			check("WebGL vendor", w.Vendor, probe.WebGLVendor)
+		}
+		if w.Renderer != "" {
+			check("WebGL renderer", w.Renderer, probe.WebGLRenderer)
+		}
+	}
+
+	// Client hints должны описывать ту же ОС и версию Chrome, что и User-Agent
+	if hints := probe.UserAgentData; hints != nil {
+		if md := UserAgentMetadata(f); md != nil {
+			check("navigator.userAgentData.platform", md.Platform, hints.Platform)
+			check("navigator.userAgentData.mobile", md.Mobile, hints.Mobile)
+			check("navigator.userAgentData.platformVersion", md.PlatformVersion, hints.PlatformVersion)
+			check("navigator.userAgentData.architecture", md.Architecture, hints.Architecture)
+			check("navigator.userAgentData.bitness", md.Bitness, hints.Bitness)
+			check("navigator.userAgentData.model", md.Model, hints.Model)
+			for _, brand := range md.Brands {
+				check("navigator.userAgentData.brands ("+brand.Brand+")", brand.Version, brandVersion(hints.Brands, brand.Brand))
+			}
+			for _, brand := range md.FullVersionList {
+				check("navigator.userAgentData.fullVersionList ("+brand.Brand+")", brand.Version, brandVersion(hints.FullVersionList, brand.Brand))
+			}
+		}
+	}
+
+	// Шум не должен меняться между отрисовками
+	check("canvas hash of repeated render", probe.CanvasHash, probe.CanvasHashRepeat)
+
+	// Воркер должен видеть те же значения, что и страница
+	w := probe.Worker
+	if w == nil {
+		check("worker", "values", "none")
+	} else {
+		check("worker navigator.userAgent", probe.UserAgent, w.UserAgent)
+		check("worker navigator.platform", probe.Platform, w.Platform)
+		check("worker navigator.languages", probe.Languages, w.Languages)
+		check("worker navigator.hardwareConcurrency", probe.HardwareConcurrency, w.HardwareConcurrency)
+		check("worker navigator.deviceMemory", probe.DeviceMemory, w.DeviceMemory)
+		check("worker Intl timeZone", probe.Timezone, w.Timezone)
+		check("worker Date.getTimezoneOffset()", probe.TimezoneOffset, w.TimezoneOffset)
+		check("worker navigator.webdriver", false, w.Webdriver)
+
+		// Хеш в воркере снят без шума: со страницей он совпадает, только если шум выключен
+		if w.CanvasHash != "" {
+			noise := f.Canvas != nil && f.Canvas.Noise > 0
+			check("canvas noise applied", noise, probe.CanvasHash != w.CanvasHash)
+		}
+	}
+
+	return mismatches
+}
+
+// brandVersion возвращает версию первого найденного бренда
+func brandVersion(brands []ProbeBrand, names ...string) string {
+	for _, name := range names {
+		for _, brand := range brands {
+			if brand.Brand == name {
+				return brand.Version
+			}
+		}
+	}
+	return ""
+}
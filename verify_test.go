@@ -0,0 +1,97 @@
+package osciris
+
+import (
+	"testing"
+	"time"
+
+	fp "github.com/vitaliitsarov/fingerprint-injector-go"
+)
+
+// testProbe возвращает результат проверочной страницы, совпадающий с fingerprint
+func testProbe(f *fp.Fingerprint) *Probe {
+	loc, _ := time.LoadLocation(f.Timezone.ID)
+	_, seconds := time.Now().In(loc).Zone()
+	scope := ProbeScope{
+		UserAgent:           f.UserAgent,
+		Platform:            f.Platform,
+		Language:            f.Language,
+		Languages:           f.Languages,
+		HardwareConcurrency: f.HardwareConcurrency,
+		DeviceMemory:        float64(f.DeviceMemory),
+		Timezone:            f.Timezone.ID,
+		TimezoneOffset:      -seconds / 60,
+		CanvasHash:          "raw",
+	}
+	worker := scope
+
+	probe := &Probe{
+		ProbeScope: scope,
+		Vendor:     f.Vendor,
+		Screen: ProbeScreen{
+			Width: f.Screen.Width, Height: f.Screen.Height,
+			AvailWidth: f.Screen.AvailWidth, AvailHeight: f.Screen.AvailHeight,
+			ColorDepth: f.Screen.ColorDepth, PixelDepth: f.Screen.PixelDepth,
+			DevicePixelRatio: f.Screen.DevicePixelRatio,
+		},
+		WebGLVendor:      f.WebGL.Vendor,
+		WebGLRenderer:    f.WebGL.Renderer,
+		CanvasHashRepeat: "raw",
+		Worker:           &worker,
+	}
+	if f.Canvas != nil && f.Canvas.Noise > 0 {
+		probe.CanvasHash, probe.CanvasHashRepeat = "noised", "noised"
+	}
+	return probe
+}
+
+// hasMismatch возвращает true, если среди mismatches есть расхождение поля field
+func hasMismatch(mismatches []Mismatch, field string) bool {
+	for _, m := range mismatches {
+		if m.Field == field {
+			return true
+		}
+	}
+	return false
+}
+
+func TestDiffProbe(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(f *fp.Fingerprint, probe *Probe)
+		field  string
+	}{
+		{"webdriver exposed", func(f *fp.Fingerprint, probe *Probe) { probe.Webdriver = true }, "navigator.webdriver"},
+		{"platform", func(f *fp.Fingerprint, probe *Probe) { probe.Platform = "Linux x86_64" }, "navigator.platform"},
+		{"device memory", func(f *fp.Fingerprint, probe *Probe) { probe.DeviceMemory = 4 }, "navigator.deviceMemory"},
+		{"timezone", func(f *fp.Fingerprint, probe *Probe) { probe.Timezone = "UTC" }, "Intl timeZone"},
+		{"timezone offset", func(f *fp.Fingerprint, probe *Probe) { probe.TimezoneOffset = 0 }, "Date.getTimezoneOffset()"},
+		{"screen", func(f *fp.Fingerprint, probe *Probe) { probe.Screen.Width = 1280 }, "screen.width"},
+		{"webgl", func(f *fp.Fingerprint, probe *Probe) { probe.WebGLRenderer = "SwiftShader" }, "WebGL renderer"},
+		{"worker missing", func(f *fp.Fingerprint, probe *Probe) { probe.Worker = nil }, "worker"},
+		{"worker user agent", func(f *fp.Fingerprint, probe *Probe) { probe.Worker.UserAgent = "HeadlessChrome" }, "worker navigator.userAgent"},
+		{"worker timezone offset", func(f *fp.Fingerprint, probe *Probe) { probe.Worker.TimezoneOffset = 0 }, "worker Date.getTimezoneOffset()"},
+		{"unstable canvas noise", func(f *fp.Fingerprint, probe *Probe) { probe.CanvasHashRepeat = "other" }, "canvas hash of repeated render"},
+		{"canvas noise missing", func(f *fp.Fingerprint, probe *Probe) {
+			f.Canvas = &fp.Canvas{Noise: 0.05}
+		}, "canvas noise applied"},
+		{"unexpected canvas noise", func(f *fp.Fingerprint, probe *Probe) {
+			probe.CanvasHash, probe.CanvasHashRepeat = "noised", "noised"
+		}, "canvas noise applied"},
+	}
+
+	f := testFingerprint()
+	b := &Browser{options: &BrowserOptions{Fingerprint: f}}
+	if mismatches := b.diffProbe(testProbe(f)); len(mismatches) != 0 {
+		t.Fatalf("matching probe: unexpected mismatches %v", mismatches)
+	}
+
+	for _, tt := range tests {
+		f := testFingerprint()
+		probe := testProbe(f)
+		tt.modify(f, probe)
+		b := &Browser{options: &BrowserOptions{Fingerprint: f}}
+		if mismatches := b.diffProbe(probe); !hasMismatch(mismatches, tt.field) {
+			t.Errorf("%s: want mismatch on %s, got %v", tt.name, tt.field, mismatches)
+		}
+	}
+}